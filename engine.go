@@ -1,15 +1,21 @@
 package sqle
 
 import (
+	"context"
 	gosql "database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"gopkg.in/sqle/sqle.v0/sql"
 	"gopkg.in/sqle/sqle.v0/sql/analyzer"
 	"gopkg.in/sqle/sqle.v0/sql/expression"
 	"gopkg.in/sqle/sqle.v0/sql/parse"
+	"gopkg.in/sqle/sqle.v0/sql/plan"
 )
 
 var (
@@ -47,6 +53,13 @@ var DefaultEngine = New()
 type Engine struct {
 	Catalog  *sql.Catalog
 	Analyzer *analyzer.Analyzer
+	// MemoryBudget is the maximum number of bytes of rows a query node keeps
+	// in memory before spilling them to disk.
+	MemoryBudget int
+
+	// schemaVersion is incremented every time the schema of the tables
+	// changes, so plans analyzed before can be invalidated.
+	schemaVersion uint64
 }
 
 // New creates a new Engine.
@@ -58,7 +71,7 @@ func New() *Engine {
 	}
 
 	a := analyzer.New(c)
-	return &Engine{c, a}
+	return &Engine{Catalog: c, Analyzer: a, MemoryBudget: plan.DefaultMemoryBudget}
 }
 
 // Open creates a new session for the engine and returns
@@ -71,16 +84,59 @@ func (e *Engine) Open(name string) (driver.Conn, error) {
 
 // Query executes a query without attaching to any session.
 func (e *Engine) Query(query string) (sql.Schema, sql.RowIter, error) {
-	parsed, err := parse.Parse(query)
+	analyzed, err := e.analyze(query)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	return e.queryNode(analyzed)
+}
+
+// Exec executes a query that doesn't return rows, such as an INSERT, without
+// attaching to any session.
+func (e *Engine) Exec(query string) (sql.Result, error) {
+	analyzed, err := e.analyze(query)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	return e.execNode(analyzed)
+}
+
+func (e *Engine) analyze(query string) (sql.Node, error) {
+	parsed, err := parse.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.analyzeNode(parsed)
+}
+
+// analyzeNode analyzes the parsed node and sets the memory budget of the
+// engine to the nodes of the resulting plan.
+func (e *Engine) analyzeNode(parsed sql.Node) (sql.Node, error) {
 	analyzed, err := e.Analyzer.Analyze(parsed)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
+	return analyzed.TransformUp(func(n sql.Node) sql.Node {
+		switch n := n.(type) {
+		case *plan.Sort:
+			n.MemoryBudget = e.MemoryBudget
+		case *plan.GroupBy:
+			n.MemoryBudget = e.MemoryBudget
+		case *plan.Distinct:
+			n.MemoryBudget = e.MemoryBudget
+		}
+
+		return n
+	}), nil
+}
+
+func (e *Engine) queryNode(analyzed sql.Node) (sql.Schema, sql.RowIter, error) {
+	defer e.checkSchemaChange(analyzed)
+
 	iter, err := analyzed.RowIter()
 	if err != nil {
 		return nil, nil, err
@@ -89,9 +145,54 @@ func (e *Engine) Query(query string) (sql.Schema, sql.RowIter, error) {
 	return analyzed.Schema(), iter, nil
 }
 
+// execNode executes the node. Nodes that are not an sql.Executor have their
+// rows read and discarded, and affect no rows.
+func (e *Engine) execNode(analyzed sql.Node) (sql.Result, error) {
+	defer e.checkSchemaChange(analyzed)
+
+	if ex, ok := analyzed.(sql.Executor); ok {
+		return ex.Execute()
+	}
+
+	iter, err := analyzed.RowIter()
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	for {
+		_, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			_ = iter.Close()
+			return sql.Result{}, err
+		}
+	}
+
+	return sql.Result{}, iter.Close()
+}
+
+// checkSchemaChange invalidates the plans analyzed before if the executed
+// node changes the schema or the indexes of the tables.
+func (e *Engine) checkSchemaChange(n sql.Node) {
+	switch n.(type) {
+	case *plan.CreateTable, *plan.DropTable,
+		*plan.AddColumn, *plan.DropColumn, *plan.RenameColumn,
+		*plan.CreateIndex:
+		e.invalidatePlans()
+	}
+}
+
+func (e *Engine) invalidatePlans() {
+	atomic.AddUint64(&e.schemaVersion, 1)
+}
+
 func (e *Engine) AddDatabase(db sql.Database) {
 	e.Catalog.Databases = append(e.Catalog.Databases, db)
 	e.Analyzer.CurrentDatabase = db.Name()
+	e.invalidatePlans()
 }
 
 // Session represents a SQL session.
@@ -103,13 +204,23 @@ type session struct {
 }
 
 // Prepare returns a prepared statement, bound to this connection.
-// Placeholders are not supported yet.
+// The query may contain positional (?) or named (:name) placeholders.
 func (s *session) Prepare(query string) (driver.Stmt, error) {
 	if err := s.checkOpen(); err != nil {
 		return nil, err
 	}
 
-	return &stmt{session: s, query: query}, nil
+	parsed, err := parse.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stmt{
+		session:  s,
+		query:    query,
+		parsed:   parsed,
+		numInput: len(placeholders(parsed)),
+	}, nil
 }
 
 // Close closes the session.
@@ -137,8 +248,12 @@ func (s *session) checkOpen() error {
 
 type stmt struct {
 	*session
-	query  string
-	closed bool
+	query    string
+	parsed   sql.Node
+	analyzed sql.Node
+	version  uint64
+	numInput int
+	closed   bool
 }
 
 // Close closes the statement.
@@ -152,23 +267,59 @@ func (s *stmt) Close() error {
 }
 
 // NumInput returns the number of placeholder parameters.
-// Always returns -1 since placeholders are not supported yet.
 func (s *stmt) NumInput() int {
-	return -1
+	return s.numInput
 }
 
 // Exec executes a query that doesn't return rows, such as an INSERT or UPDATE.
 func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
-	return nil, ErrNotSupported
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// ExecContext executes a query that doesn't return rows, such as an INSERT or
+// UPDATE, binding the given arguments to its placeholders.
+func (s *stmt) ExecContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Result, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	node, err := s.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := s.execNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return result{r}, nil
 }
 
 // Query executes a query that may return rows, such as a SELECT.
 func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
-	if len(args) > 0 {
-		return nil, ErrNotSupported
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// QueryContext executes a query that may return rows, such as a SELECT,
+// binding the given arguments to its placeholders.
+func (s *stmt) QueryContext(
+	ctx context.Context,
+	args []driver.NamedValue,
+) (driver.Rows, error) {
+	if err := s.checkOpen(); err != nil {
+		return nil, err
 	}
 
-	schema, iter, err := s.session.Engine.Query(s.query)
+	node, err := s.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, iter, err := s.queryNode(node)
 	if err != nil {
 		return nil, err
 	}
@@ -176,6 +327,116 @@ func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
 	return &rows{schema: schema, iter: iter}, nil
 }
 
+// bind returns the analyzed plan of the statement with the placeholders
+// replaced by the given arguments. Without placeholders, the plan is analyzed
+// only the first time, or again if the schema of the tables changed since.
+// Otherwise, it's analyzed every time once the placeholders are replaced, so
+// rules such as index lookups and pushdown can use their values.
+func (s *stmt) bind(args []driver.NamedValue) (sql.Node, error) {
+	if s.numInput == 0 {
+		version := atomic.LoadUint64(&s.session.Engine.schemaVersion)
+		if s.analyzed == nil || s.version != version {
+			analyzed, err := s.session.Engine.analyzeNode(s.parsed)
+			if err != nil {
+				return nil, err
+			}
+
+			s.analyzed = analyzed
+			s.version = version
+		}
+
+		return s.analyzed, nil
+	}
+
+	values := make(map[string]*expression.Literal, len(args))
+	for _, arg := range args {
+		name := arg.Name
+		if name == "" {
+			name = "v" + strconv.Itoa(arg.Ordinal)
+		}
+
+		l, err := valueToLiteral(arg.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for placeholder :%s: %s", name, err)
+		}
+
+		values[name] = l
+	}
+
+	var err error
+	node := s.parsed.TransformExpressionsUp(func(e sql.Expression) sql.Expression {
+		p, ok := e.(*expression.Placeholder)
+		if !ok {
+			return e
+		}
+
+		l, ok := values[p.Placeholder()]
+		if !ok {
+			err = fmt.Errorf("no value bound to placeholder %s", p.Name())
+			return e
+		}
+
+		return l
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.session.Engine.analyzeNode(node)
+}
+
+// placeholders returns the distinct names of the placeholders in the node.
+func placeholders(n sql.Node) map[string]struct{} {
+	names := make(map[string]struct{})
+	n.TransformExpressionsUp(func(e sql.Expression) sql.Expression {
+		if p, ok := e.(*expression.Placeholder); ok {
+			names[p.Placeholder()] = struct{}{}
+		}
+
+		return e
+	})
+
+	return names
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+
+	return named
+}
+
+// valueToLiteral converts a value bound to a placeholder into a literal
+// of the matching SQL type.
+func valueToLiteral(v driver.Value) (*expression.Literal, error) {
+	var typ sql.Type
+	switch v.(type) {
+	case nil:
+		return expression.NewLiteral(nil, sql.Null), nil
+	case int64:
+		typ = sql.BigInteger
+	case float64:
+		typ = sql.Float
+	case bool:
+		typ = sql.Boolean
+	case string, []byte:
+		typ = sql.String
+	case time.Time:
+		typ = sql.TimestampWithTimezone
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+
+	value, err := typ.Convert(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return expression.NewLiteral(value, typ), nil
+}
+
 func (s *stmt) checkOpen() error {
 	if s.closed {
 		return driver.ErrBadConn
@@ -184,6 +445,23 @@ func (s *stmt) checkOpen() error {
 	return nil
 }
 
+// result is the outcome of an Exec.
+// It implements the standard database/sql/driver/Result interface.
+type result struct {
+	sql.Result
+}
+
+// LastInsertId returns the last key generated by the table for an inserted
+// row, or 0 if it does not generate keys.
+func (r result) LastInsertId() (int64, error) {
+	return r.Result.LastInsertID, nil
+}
+
+// RowsAffected returns the number of rows inserted, updated or deleted.
+func (r result) RowsAffected() (int64, error) {
+	return r.Result.RowsAffected, nil
+}
+
 type rows struct {
 	schema sql.Schema
 	iter   sql.RowIter
@@ -2,11 +2,16 @@ package sqle_test
 
 import (
 	gosql "database/sql"
+	"errors"
 	"testing"
 
 	"gopkg.in/sqle/sqle.v0"
 	"gopkg.in/sqle/sqle.v0/mem"
 	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/analyzer"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+	"gopkg.in/sqle/sqle.v0/sql/parse"
+	"gopkg.in/sqle/sqle.v0/sql/plan"
 
 	"github.com/stretchr/testify/require"
 )
@@ -43,6 +48,26 @@ func TestQueries(t *testing.T) {
 		[][]interface{}{{int64(1)}},
 	)
 
+	testQuery(t, e,
+		"SELECT i FROM mytable WHERE s = 'a' OR i = 3 ORDER BY i;",
+		[][]interface{}{{int64(1)}, {int64(3)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i FROM mytable WHERE s = 'b' AND i = 2;",
+		[][]interface{}{{int64(2)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i * 2 + 1 FROM mytable WHERE i % 2 = 1 ORDER BY i;",
+		[][]interface{}{{int64(3)}, {int64(7)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i / 2 FROM mytable WHERE i = 3;",
+		[][]interface{}{{float64(1.5)}},
+	)
+
 	testQuery(t, e,
 		"SELECT COUNT(*) FROM mytable;",
 		[][]interface{}{{int64(3)}},
@@ -59,6 +84,306 @@ func TestQueries(t *testing.T) {
 	)
 }
 
+func TestStar(t *testing.T) {
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT i * 10, * FROM mytable WHERE i < 3 ORDER BY i;",
+		[][]interface{}{{int64(10), int64(1), "a"}, {int64(20), int64(2), "b"}},
+	)
+
+	testQuery(t, e,
+		"SELECT o.*, COUNT(*) FROM othertable o WHERE fk = 3 GROUP BY fk, name;",
+		[][]interface{}{{int64(3), "three", int64(1)}},
+	)
+}
+
+func TestDistinct(t *testing.T) {
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT DISTINCT fk FROM othertable ORDER BY fk;",
+		[][]interface{}{{int64(1)}, {int64(3)}, {int64(4)}},
+	)
+
+	testQuery(t, e,
+		"SELECT COUNT(DISTINCT fk), COUNT(fk) FROM othertable;",
+		[][]interface{}{{int64(3), int64(4)}},
+	)
+
+	testQuery(t, e,
+		`SELECT i, COUNT(DISTINCT name) FROM mytable JOIN othertable ON i = fk
+		WHERE i = 1 GROUP BY i;`,
+		[][]interface{}{{int64(1), int64(2)}},
+	)
+}
+
+func TestAggregation_EmptyInput(t *testing.T) {
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT COUNT(*) FROM mytable WHERE i > 10;",
+		[][]interface{}{{int64(0)}},
+	)
+
+	testQuery(t, e,
+		"SELECT COUNT(*), COUNT(DISTINCT s) FROM mytable WHERE 1 = 0;",
+		[][]interface{}{{int64(0), int64(0)}},
+	)
+}
+
+func TestHaving(t *testing.T) {
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT fk, COUNT(*) AS c FROM othertable GROUP BY fk HAVING c > 1;",
+		[][]interface{}{{int64(1), int64(2)}},
+	)
+
+	testQuery(t, e,
+		"SELECT fk FROM othertable GROUP BY fk HAVING COUNT(*) > 1;",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT fk FROM othertable GROUP BY fk HAVING COUNT(DISTINCT name) = 2 AND fk < 3;",
+		[][]interface{}{{int64(1)}},
+	)
+}
+
+func TestLimitOffset(t *testing.T) {
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT i FROM mytable ORDER BY i LIMIT 1 OFFSET 1;",
+		[][]interface{}{{int64(2)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i FROM mytable ORDER BY i LIMIT 1, 5;",
+		[][]interface{}{{int64(2)}, {int64(3)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i FROM mytable ORDER BY i LIMIT 5 OFFSET 2;",
+		[][]interface{}{{int64(3)}},
+	)
+
+	testQuery(t, e,
+		"SELECT s, i FROM mytable ORDER BY i DESC LIMIT 2 OFFSET 1;",
+		[][]interface{}{{"b", int64(2)}, {"a", int64(1)}},
+	)
+}
+
+func TestMemoryBudget(t *testing.T) {
+	e := newEngine(t)
+	e.MemoryBudget = 1
+
+	testQuery(t, e,
+		"SELECT s, i FROM mytable ORDER BY s DESC;",
+		[][]interface{}{{"c", int64(3)}, {"b", int64(2)}, {"a", int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT COUNT(*), COUNT(DISTINCT fk), COUNT(DISTINCT name) FROM othertable;",
+		[][]interface{}{{int64(4), int64(3), int64(4)}},
+	)
+
+	testQuery(t, e,
+		"SELECT DISTINCT fk FROM othertable ORDER BY fk DESC;",
+		[][]interface{}{{int64(4)}, {int64(3)}, {int64(1)}},
+	)
+}
+
+func TestPlaceholders(t *testing.T) {
+	require := require.New(t)
+
+	sqle.DefaultEngine = newEngine(t)
+	db, err := gosql.Open(sqle.DriverName, "")
+	require.NoError(err)
+	defer func() { require.NoError(db.Close()) }()
+
+	queryInts := func(q string, args ...interface{}) []int64 {
+		rows, err := db.Query(q, args...)
+		require.NoError(err)
+		defer func() { require.NoError(rows.Close()) }()
+
+		var result []int64
+		for rows.Next() {
+			var i int64
+			require.NoError(rows.Scan(&i))
+			result = append(result, i)
+		}
+		require.NoError(rows.Err())
+
+		return result
+	}
+
+	require.Equal(
+		[]int64{2},
+		queryInts("SELECT i FROM mytable WHERE s = ?;", "b"),
+	)
+	require.Equal(
+		[]int64{2, 3},
+		queryInts("SELECT i FROM mytable WHERE i > ? ORDER BY i;", 1),
+	)
+	require.Equal(
+		[]int64{3},
+		queryInts("SELECT i FROM mytable WHERE i > :min AND s <> :s;",
+			gosql.Named("min", 1), gosql.Named("s", "b")),
+	)
+	require.Equal(
+		[]int64{2, 3},
+		queryInts("SELECT i FROM mytable ORDER BY i LIMIT ?, ?;", 1, 5),
+	)
+
+	stmt, err := db.Prepare("SELECT i FROM mytable WHERE i = ?;")
+	require.NoError(err)
+	defer func() { require.NoError(stmt.Close()) }()
+
+	for _, i := range []int64{1, 3} {
+		var result int64
+		require.NoError(stmt.QueryRow(i).Scan(&result))
+		require.Equal(i, result)
+	}
+
+	_, err = stmt.Query()
+	require.Error(err)
+
+	_, err = db.Query("SELECT i FROM mytable WHERE i = :i;", 1)
+	require.Error(err)
+}
+
+func TestJoins(t *testing.T) {
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT i, name FROM mytable JOIN othertable ON i = fk ORDER BY name;",
+		[][]interface{}{{int64(1), "one"}, {int64(3), "three"}, {int64(1), "uno"}},
+	)
+
+	testQuery(t, e,
+		"SELECT i, name FROM mytable LEFT JOIN othertable ON i = fk ORDER BY i, name;",
+		[][]interface{}{{int64(1), "one"}, {int64(1), "uno"}, {int64(2), nil}, {int64(3), "three"}},
+	)
+
+	testQuery(t, e,
+		"SELECT i, name FROM mytable, othertable WHERE fk = i AND s <> 'c';",
+		[][]interface{}{{int64(1), "one"}, {int64(1), "uno"}},
+	)
+
+	testQuery(t, e,
+		"SELECT i, name FROM mytable RIGHT JOIN othertable ON i = fk WHERE i IS NULL;",
+		[][]interface{}{{nil, "four"}},
+	)
+
+	testQuery(t, e,
+		`SELECT a.i, b.i FROM mytable a JOIN mytable AS b ON a.i = b.i + 1
+		ORDER BY a.i;`,
+		[][]interface{}{{int64(2), int64(1)}, {int64(3), int64(2)}},
+	)
+
+	testQuery(t, e,
+		`SELECT mytable.i, othertable.name FROM mydb.mytable, othertable
+		WHERE othertable.fk = mytable.i AND mytable.s = 'c';`,
+		[][]interface{}{{int64(3), "three"}},
+	)
+
+	testQuery(t, e,
+		"SELECT mytable.*, name FROM mytable JOIN othertable ON i = fk WHERE fk = 3;",
+		[][]interface{}{{int64(3), "c", "three"}},
+	)
+
+	testQuery(t, e,
+		"SELECT * FROM mytable a JOIN colors c ON a.i = c.color_i ORDER BY i;",
+		[][]interface{}{
+			{int64(1), "a", int64(1), "red"},
+			{int64(3), "c", int64(3), "blue"},
+		},
+	)
+
+	testQuery(t, e,
+		`SELECT i, name, color FROM mytable, othertable, colors
+		WHERE fk = i AND color_i = i ORDER BY name;`,
+		[][]interface{}{
+			{int64(1), "one", "red"},
+			{int64(3), "three", "blue"},
+			{int64(1), "uno", "red"},
+		},
+	)
+}
+
+func TestJoins_Using(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	for _, q := range []string{
+		"CREATE TABLE l (a INT, b INT);",
+		"CREATE TABLE r (b INT, c TEXT);",
+		"INSERT INTO l (a, b) VALUES (1, 10), (2, 20);",
+		"INSERT INTO r (b, c) VALUES (10, 'x'), (30, 'z');",
+	} {
+		_, err := e.Exec(q)
+		require.NoError(err)
+	}
+
+	testQuery(t, e,
+		"SELECT b FROM l JOIN r USING (b);",
+		[][]interface{}{{int64(10)}},
+	)
+
+	testQuery(t, e,
+		"SELECT * FROM l LEFT JOIN r USING (b) ORDER BY a;",
+		[][]interface{}{{int64(10), int64(1), "x"}, {int64(20), int64(2), nil}},
+	)
+
+	testQuery(t, e,
+		"SELECT * FROM l RIGHT JOIN r USING (b) ORDER BY b;",
+		[][]interface{}{{int64(10), "x", int64(1)}, {int64(30), "z", nil}},
+	)
+
+	_, _, err := e.Query("SELECT * FROM l JOIN r USING (a);")
+	require.EqualError(err, "column a not found in both tables")
+}
+
+func TestAmbiguousColumns(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, _, err := e.Query("SELECT i FROM mytable a, mytable b;")
+	require.EqualError(err, "ambiguous column name i")
+
+	testQuery(t, e,
+		"SELECT a.i FROM mytable a, mytable b WHERE a.i = b.i AND a.i = 1;",
+		[][]interface{}{{int64(1)}},
+	)
+}
+
+func TestJoins_MixedNumericTypes(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	for _, q := range []string{
+		"CREATE TABLE hi (a INT);",
+		"CREATE TABLE hf (f FLOAT);",
+		"INSERT INTO hi (a) VALUES (1), (2);",
+		"INSERT INTO hf (f) VALUES (1.5), (2);",
+	} {
+		_, err := e.Exec(q)
+		require.NoError(err)
+	}
+
+	testQuery(t, e,
+		"SELECT a, f FROM hi, hf WHERE a = f;",
+		[][]interface{}{{int64(2), float64(2)}},
+	)
+
+	testQuery(t, e,
+		"SELECT f, a FROM hf JOIN hi ON f = a;",
+		[][]interface{}{{float64(2), int64(2)}},
+	)
+}
+
 func TestInsertInto(t *testing.T) {
 	e := newEngine(t)
 	testQuery(t, e,
@@ -72,6 +397,468 @@ func TestInsertInto(t *testing.T) {
 	)
 }
 
+func TestInsertInto_OnDuplicateKeyUpdate(t *testing.T) {
+	e := newEngine(t)
+
+	// tables without keys never have duplicates
+	testQuery(t, e,
+		"INSERT INTO mytable (i, s) VALUES (1, 'a') ON DUPLICATE KEY UPDATE s = VALUES(s);",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"INSERT IGNORE INTO mytable (i, s) VALUES (1, 'a');",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT COUNT(*) FROM mytable WHERE i = 1;",
+		[][]interface{}{{int64(3)}},
+	)
+}
+
+func TestKeys(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE t (a INT PRIMARY KEY, b TEXT UNIQUE);")
+	require.NoError(err)
+
+	_, err = e.Exec("INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y');")
+	require.NoError(err)
+
+	var dup *sql.DuplicateKeyError
+	_, err = e.Exec("INSERT INTO t (a, b) VALUES (1, 'z');")
+	require.True(errors.As(err, &dup))
+	require.Equal("PRIMARY", dup.Key)
+
+	_, err = e.Exec("UPDATE t SET b = 'x' WHERE a = 2;")
+	require.True(errors.As(err, &dup))
+	require.Equal("b", dup.Key)
+
+	result, err := e.Exec("INSERT IGNORE INTO t (a, b) VALUES (1, 'z'), (3, 'z');")
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 1, Inserted: 1, Skipped: 1}, result)
+
+	result, err = e.Exec("REPLACE INTO t (a, b) VALUES (3, 'w');")
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 2, Updated: 1}, result)
+
+	result, err = e.Exec(
+		"INSERT INTO t (a, b) VALUES (2, 'v') ON DUPLICATE KEY UPDATE b = VALUES(b);",
+	)
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 2, Updated: 1}, result)
+
+	testQuery(t, e,
+		"SELECT a, b FROM t ORDER BY a;",
+		[][]interface{}{{int64(1), "x"}, {int64(2), "v"}, {int64(3), "w"}},
+	)
+}
+
+func TestIndexes(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE t (a INT, b TEXT);")
+	require.NoError(err)
+
+	_, err = e.Exec("INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y'), (3, 'x'), (4, 'z');")
+	require.NoError(err)
+
+	_, err = e.Exec("CREATE INDEX a_idx ON t (a);")
+	require.NoError(err)
+
+	_, err = e.Exec("CREATE INDEX b_idx USING HASH ON t (b);")
+	require.NoError(err)
+
+	parsed, err := parse.Parse("SELECT a FROM t WHERE b = 'x';")
+	require.NoError(err)
+	analyzed, err := e.Analyzer.Analyze(parsed)
+	require.NoError(err)
+
+	var lookups int
+	analyzed.TransformUp(func(n sql.Node) sql.Node {
+		if _, ok := n.(*plan.IndexLookup); ok {
+			lookups++
+		}
+		return n
+	})
+	require.Equal(1, lookups)
+
+	testQuery(t, e,
+		"SELECT a FROM t WHERE b = 'x';",
+		[][]interface{}{{int64(1)}, {int64(3)}},
+	)
+
+	testQuery(t, e,
+		"SELECT b FROM t WHERE a > 1 AND a <= 3;",
+		[][]interface{}{{"y"}, {"x"}},
+	)
+
+	_, err = e.Exec("UPDATE t SET b = 'w' WHERE a = 2;")
+	require.NoError(err)
+
+	_, err = e.Exec("DELETE FROM t WHERE b = 'x';")
+	require.NoError(err)
+
+	testQuery(t, e,
+		"SELECT a, b FROM t WHERE a >= 1;",
+		[][]interface{}{{int64(2), "w"}, {int64(4), "z"}},
+	)
+}
+
+func TestIndexes_Placeholders(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE t (a INT, b TEXT);")
+	require.NoError(err)
+
+	_, err = e.Exec("INSERT INTO t (a, b) VALUES (1, 'x'), (2, 'y');")
+	require.NoError(err)
+
+	_, err = e.Exec("CREATE INDEX a_idx ON t (a);")
+	require.NoError(err)
+
+	// the last rule records whether the analyzed plan looks up the index
+	var lookup bool
+	rules := append([]analyzer.Rule{}, e.Analyzer.Rules...)
+	e.Analyzer.Rules = append(rules, analyzer.Rule{
+		Name: "find_index_lookup",
+		Apply: func(a *analyzer.Analyzer, n sql.Node) sql.Node {
+			lookup = false
+			n.TransformUp(func(n sql.Node) sql.Node {
+				if _, ok := n.(*plan.IndexLookup); ok {
+					lookup = true
+				}
+				return n
+			})
+			return n
+		},
+	})
+
+	sqle.DefaultEngine = e
+	db, err := gosql.Open(sqle.DriverName, "")
+	require.NoError(err)
+	defer func() { require.NoError(db.Close()) }()
+
+	stmt, err := db.Prepare("SELECT b FROM t WHERE a = ?;")
+	require.NoError(err)
+	defer func() { require.NoError(stmt.Close()) }()
+
+	var b string
+	require.NoError(stmt.QueryRow(2).Scan(&b))
+	require.Equal("y", b)
+	require.True(lookup)
+}
+
+func TestConstantFolding(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+
+	testQuery(t, e,
+		"SELECT i FROM mytable WHERE 1 = 1 AND i > 1 + 1;",
+		[][]interface{}{{int64(3)}},
+	)
+
+	schema, iter, err := e.Query("SELECT i, s FROM mytable WHERE 1 = 0;")
+	require.NoError(err)
+	require.Len(schema, 2)
+	rows, err := sql.RowIterToRows(iter)
+	require.NoError(err)
+	require.Len(rows, 0)
+
+	result, err := e.Exec("UPDATE mytable SET s = 'x' WHERE NOT 1 = 1;")
+	require.NoError(err)
+	require.Equal(int64(0), result.RowsAffected)
+}
+
+func TestInsertInto_Defaults(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE t (a FLOAT NOT NULL, b TEXT DEFAULT 'none', c INT);")
+	require.NoError(err)
+
+	testQuery(t, e,
+		"INSERT INTO t (a) VALUES (1);",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT a, b, c FROM t;",
+		[][]interface{}{{float64(1), "none", nil}},
+	)
+
+	_, err = e.Exec("INSERT INTO t (a, b) VALUES (NULL, 'x');")
+	require.Error(err)
+	require.Contains(err.Error(), "column a")
+}
+
+func TestInsertInto_Columns(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE t (a INT PRIMARY KEY, b TEXT DEFAULT 'z', c FLOAT);")
+	require.NoError(err)
+
+	result, err := e.Exec("INSERT INTO t VALUES (1, 'x', 1.5);")
+	require.NoError(err)
+	require.Equal(int64(1), result.RowsAffected)
+
+	_, err = e.Exec("REPLACE INTO t VALUES (1, 'y', 2.5);")
+	require.NoError(err)
+
+	testQuery(t, e,
+		"SELECT a, b, c FROM t;",
+		[][]interface{}{{int64(1), "y", float64(2.5)}},
+	)
+
+	_, err = e.Exec("INSERT INTO t VALUES (2, 'x');")
+	require.EqualError(err, "column count doesn't match value count: 3 columns and 2 values")
+
+	_, err = e.Exec("INSERT INTO t (a, b) VALUES (2, 'x', 1.5);")
+	require.EqualError(err, "column count doesn't match value count: 2 columns and 3 values")
+
+	_, err = e.Exec("INSERT INTO t (a, nonexistent) VALUES (2, 'x');")
+	require.EqualError(err, "column not found: nonexistent")
+
+	testQuery(t, e,
+		"SELECT COUNT(*) FROM t;",
+		[][]interface{}{{int64(1)}},
+	)
+}
+
+func TestExec(t *testing.T) {
+	require := require.New(t)
+
+	sqle.DefaultEngine = newEngine(t)
+	db, err := gosql.Open(sqle.DriverName, "")
+	require.NoError(err)
+	defer func() { require.NoError(db.Close()) }()
+
+	result, err := db.Exec(
+		"INSERT INTO mytable (i, s) VALUES (?, ?), (?, ?);",
+		10, "x", 11, "y",
+	)
+	require.NoError(err)
+
+	n, err := result.RowsAffected()
+	require.NoError(err)
+	require.Equal(int64(2), n)
+
+	id, err := result.LastInsertId()
+	require.NoError(err)
+	require.Equal(int64(0), id)
+
+	var count int64
+	err = db.QueryRow("SELECT COUNT(*) FROM mytable WHERE i >= 10;").Scan(&count)
+	require.NoError(err)
+	require.Equal(int64(2), count)
+
+	result, err = db.Exec("SELECT i FROM mytable;")
+	require.NoError(err)
+
+	n, err = result.RowsAffected()
+	require.NoError(err)
+	require.Equal(int64(0), n)
+}
+
+func TestEngine_Exec(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	result, err := e.Exec("INSERT INTO mytable (s, i) VALUES ('x', 999);")
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 1, Inserted: 1}, result)
+}
+
+func TestUpdate(t *testing.T) {
+	e := newEngine(t)
+	testQuery(t, e,
+		"UPDATE mytable SET s = 'updated', i = i * 10 WHERE i > 1;",
+		[][]interface{}{{int64(2)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i, s FROM mytable ORDER BY i;",
+		[][]interface{}{
+			{int64(1), "a"},
+			{int64(20), "updated"},
+			{int64(30), "updated"},
+		},
+	)
+
+	testQuery(t, e,
+		"UPDATE mytable SET s = 'first' ORDER BY i DESC LIMIT 1;",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT i FROM mytable WHERE s = 'first';",
+		[][]interface{}{{int64(30)}},
+	)
+}
+
+func TestUpdate_SelfReferencing(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE u (a INT);")
+	require.NoError(err)
+	_, err = e.Exec("INSERT INTO u (a) VALUES (1), (2), (3);")
+	require.NoError(err)
+
+	result, err := e.Exec("UPDATE u SET a = a + 1;")
+	require.NoError(err)
+	require.Equal(int64(3), result.RowsAffected)
+
+	testQuery(t, e,
+		"SELECT a FROM u ORDER BY a;",
+		[][]interface{}{{int64(2)}, {int64(3)}, {int64(4)}},
+	)
+}
+
+func TestDelete_EqualRows(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE d (a INT);")
+	require.NoError(err)
+	_, err = e.Exec("INSERT INTO d (a) VALUES (1), (2), (1), (1);")
+	require.NoError(err)
+
+	result, err := e.Exec("DELETE FROM d WHERE a = 1;")
+	require.NoError(err)
+	require.Equal(int64(3), result.RowsAffected)
+
+	testQuery(t, e,
+		"SELECT a FROM d;",
+		[][]interface{}{{int64(2)}},
+	)
+}
+
+func TestDelete(t *testing.T) {
+	e := newEngine(t)
+	testQuery(t, e,
+		"DELETE FROM othertable WHERE fk = 1 ORDER BY name LIMIT 1;",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT name FROM othertable WHERE fk = 1;",
+		[][]interface{}{{"uno"}},
+	)
+
+	testQuery(t, e,
+		"DELETE FROM othertable WHERE fk > 1;",
+		[][]interface{}{{int64(2)}},
+	)
+
+	testQuery(t, e,
+		"TRUNCATE TABLE othertable;",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"INSERT INTO othertable (fk, name) VALUES (5, 'five');",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT fk, name FROM othertable;",
+		[][]interface{}{{int64(5), "five"}},
+	)
+}
+
+func TestCreateDropTable(t *testing.T) {
+	require := require.New(t)
+
+	e := newEngine(t)
+	_, err := e.Exec("CREATE TABLE newtable (a BIGINT NOT NULL, b TEXT DEFAULT 'none');")
+	require.NoError(err)
+
+	_, err = e.Exec("CREATE TABLE newtable (a INT);")
+	require.Error(err)
+
+	_, err = e.Exec("CREATE TABLE IF NOT EXISTS newtable (a INT);")
+	require.NoError(err)
+
+	testQuery(t, e,
+		"INSERT INTO newtable (a, b) VALUES (1, 'one');",
+		[][]interface{}{{int64(1)}},
+	)
+
+	testQuery(t, e,
+		"SELECT a, b FROM newtable;",
+		[][]interface{}{{int64(1), "one"}},
+	)
+
+	_, err = e.Exec("DROP TABLE newtable;")
+	require.NoError(err)
+
+	_, err = e.Exec("DROP TABLE newtable;")
+	require.Error(err)
+
+	_, err = e.Exec("DROP TABLE IF EXISTS newtable;")
+	require.NoError(err)
+}
+
+func TestAlterTable(t *testing.T) {
+	require := require.New(t)
+
+	sqle.DefaultEngine = newEngine(t)
+	db, err := gosql.Open(sqle.DriverName, "")
+	require.NoError(err)
+	defer func() { require.NoError(db.Close()) }()
+
+	stmt, err := db.Prepare("SELECT * FROM colors WHERE color_i = ?;")
+	require.NoError(err)
+	defer func() { require.NoError(stmt.Close()) }()
+
+	columns := func() []string {
+		rows, err := stmt.Query(1)
+		require.NoError(err)
+		defer func() { require.NoError(rows.Close()) }()
+
+		cols, err := rows.Columns()
+		require.NoError(err)
+		return cols
+	}
+
+	require.Equal([]string{"color_i", "color"}, columns())
+
+	_, err = db.Exec("ALTER TABLE colors ADD COLUMN hex TEXT DEFAULT '#000000';")
+	require.NoError(err)
+
+	require.Equal([]string{"color_i", "color", "hex"}, columns())
+
+	var hex string
+	require.NoError(stmt.QueryRow(3).Scan(new(int64), new(string), &hex))
+	require.Equal("#000000", hex)
+
+	_, err = db.Exec("ALTER TABLE colors RENAME COLUMN color TO name;")
+	require.NoError(err)
+
+	_, err = db.Exec("ALTER TABLE colors DROP COLUMN hex;")
+	require.NoError(err)
+
+	require.Equal([]string{"color_i", "name"}, columns())
+}
+
+func TestDivisionByZero(t *testing.T) {
+	assert := require.New(t)
+
+	e := newEngine(t)
+	_, iter, err := e.Query("SELECT i / 0 FROM mytable;")
+	assert.NoError(err)
+
+	_, err = sql.RowIterToRows(iter)
+	assert.Equal(expression.ErrDivisionByZero, err)
+}
+
 func testQuery(t *testing.T, e *sqle.Engine, q string, r [][]interface{}) {
 	t.Run(q, func(t *testing.T) {
 		assert := require.New(t)
@@ -125,8 +912,26 @@ func newEngine(t *testing.T) *sqle.Engine {
 	assert.Nil(table.Insert(sql.NewRow(int64(2), "b")))
 	assert.Nil(table.Insert(sql.NewRow(int64(3), "c")))
 
+	other := mem.NewTable("othertable", sql.Schema{
+		{Name: "fk", Type: sql.BigInteger},
+		{Name: "name", Type: sql.String},
+	})
+	assert.Nil(other.Insert(sql.NewRow(int64(1), "one")))
+	assert.Nil(other.Insert(sql.NewRow(int64(1), "uno")))
+	assert.Nil(other.Insert(sql.NewRow(int64(3), "three")))
+	assert.Nil(other.Insert(sql.NewRow(int64(4), "four")))
+
+	colors := mem.NewTable("colors", sql.Schema{
+		{Name: "color_i", Type: sql.BigInteger},
+		{Name: "color", Type: sql.String},
+	})
+	assert.Nil(colors.Insert(sql.NewRow(int64(1), "red")))
+	assert.Nil(colors.Insert(sql.NewRow(int64(3), "blue")))
+
 	db := mem.NewDatabase("mydb")
 	db.AddTable("mytable", table)
+	db.AddTable("othertable", other)
+	db.AddTable("colors", colors)
 
 	e := sqle.New()
 	e.AddDatabase(db)
@@ -0,0 +1,291 @@
+package mem
+
+import "sort"
+
+// btreeDegree is the minimum degree of the nodes of a btree. Every node but
+// the root has between btreeDegree-1 and 2*btreeDegree-1 items.
+const btreeDegree = 16
+
+const btreeMaxItems = 2*btreeDegree - 1
+
+// btreeItem is an entry of a btree with its key and value.
+type btreeItem struct {
+	key   interface{}
+	value interface{}
+}
+
+// btree is a B-tree mapping keys to values, ordered by the given compare
+// function.
+type btree struct {
+	root    *btreeNode
+	compare func(a, b interface{}) int
+	len     int
+}
+
+type btreeNode struct {
+	items    []*btreeItem
+	children []*btreeNode
+}
+
+func newBTree(compare func(a, b interface{}) int) *btree {
+	return &btree{root: &btreeNode{}, compare: compare}
+}
+
+// Len returns the number of items in the tree.
+func (t *btree) Len() int {
+	return t.len
+}
+
+// Get returns the item with the given key, or nil if there is none.
+func (t *btree) Get(key interface{}) *btreeItem {
+	n := t.root
+	for {
+		i, found := n.find(key, t.compare)
+		if found {
+			return n.items[i]
+		}
+
+		if n.leaf() {
+			return nil
+		}
+
+		n = n.children[i]
+	}
+}
+
+// GetOrInsert returns the item with the given key, inserting an item with a
+// nil value if there is none.
+func (t *btree) GetOrInsert(key interface{}) *btreeItem {
+	if item := t.Get(key); item != nil {
+		return item
+	}
+
+	if len(t.root.items) == btreeMaxItems {
+		t.root = &btreeNode{children: []*btreeNode{t.root}}
+		t.root.split(0)
+	}
+
+	item := &btreeItem{key: key}
+	t.root.insert(item, t.compare)
+	t.len++
+	return item
+}
+
+// Delete removes the item with the given key and reports whether it was in
+// the tree.
+func (t *btree) Delete(key interface{}) bool {
+	if !t.root.remove(key, t.compare) {
+		return false
+	}
+
+	if len(t.root.items) == 0 && !t.root.leaf() {
+		t.root = t.root.children[0]
+	}
+
+	t.len--
+	return true
+}
+
+// Ascend calls fn for the items with a key greater than or equal to from in
+// ascending order, or for all of them if from is nil, until fn returns
+// false.
+func (t *btree) Ascend(from interface{}, fn func(*btreeItem) bool) {
+	t.root.ascend(from, t.compare, fn)
+}
+
+func (n *btreeNode) leaf() bool {
+	return len(n.children) == 0
+}
+
+// find returns the index of the first item of the node with a key greater
+// than or equal to the given one, and whether it's equal.
+func (n *btreeNode) find(key interface{}, compare func(a, b interface{}) int) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool {
+		return compare(n.items[i].key, key) >= 0
+	})
+
+	return i, i < len(n.items) && compare(n.items[i].key, key) == 0
+}
+
+// split splits the full child i of the node in two, moving its middle item
+// to the node.
+func (n *btreeNode) split(i int) {
+	child := n.children[i]
+	mid := btreeDegree - 1
+	item := child.items[mid]
+
+	right := &btreeNode{
+		items: append([]*btreeItem(nil), child.items[mid+1:]...),
+	}
+
+	if !child.leaf() {
+		right.children = append([]*btreeNode(nil), child.children[mid+1:]...)
+		child.children = child.children[:mid+1]
+	}
+
+	child.items = child.items[:mid]
+
+	n.items = append(n.items, nil)
+	copy(n.items[i+1:], n.items[i:])
+	n.items[i] = item
+
+	n.children = append(n.children, nil)
+	copy(n.children[i+2:], n.children[i+1:])
+	n.children[i+1] = right
+}
+
+// insert adds the item to the subtree of a node that is not full.
+func (n *btreeNode) insert(item *btreeItem, compare func(a, b interface{}) int) {
+	i, _ := n.find(item.key, compare)
+	if n.leaf() {
+		n.items = append(n.items, nil)
+		copy(n.items[i+1:], n.items[i:])
+		n.items[i] = item
+		return
+	}
+
+	if len(n.children[i].items) == btreeMaxItems {
+		n.split(i)
+		if compare(item.key, n.items[i].key) > 0 {
+			i++
+		}
+	}
+
+	n.children[i].insert(item, compare)
+}
+
+// remove removes the item with the given key from the subtree of the node.
+// Except for the root, the node must have at least btreeDegree items, so
+// one can be removed without merging on the way back.
+func (n *btreeNode) remove(key interface{}, compare func(a, b interface{}) int) bool {
+	i, found := n.find(key, compare)
+	if n.leaf() {
+		if found {
+			n.items = append(n.items[:i], n.items[i+1:]...)
+		}
+
+		return found
+	}
+
+	if found {
+		switch {
+		case len(n.children[i].items) >= btreeDegree:
+			pred := n.children[i].max()
+			n.items[i] = pred
+			return n.children[i].remove(pred.key, compare)
+		case len(n.children[i+1].items) >= btreeDegree:
+			succ := n.children[i+1].min()
+			n.items[i] = succ
+			return n.children[i+1].remove(succ.key, compare)
+		default:
+			n.merge(i)
+			return n.children[i].remove(key, compare)
+		}
+	}
+
+	if len(n.children[i].items) < btreeDegree {
+		i = n.grow(i)
+	}
+
+	return n.children[i].remove(key, compare)
+}
+
+// grow makes child i of the node have at least btreeDegree items, by
+// moving an item from a sibling or merging it with one. It returns the new
+// index of the child.
+func (n *btreeNode) grow(i int) int {
+	child := n.children[i]
+
+	if i > 0 && len(n.children[i-1].items) >= btreeDegree {
+		left := n.children[i-1]
+		last := len(left.items) - 1
+
+		child.items = append([]*btreeItem{n.items[i-1]}, child.items...)
+		n.items[i-1] = left.items[last]
+		left.items = left.items[:last]
+
+		if !left.leaf() {
+			child.children = append([]*btreeNode{left.children[last+1]}, child.children...)
+			left.children = left.children[:last+1]
+		}
+
+		return i
+	}
+
+	if i < len(n.items) && len(n.children[i+1].items) >= btreeDegree {
+		right := n.children[i+1]
+
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.items = right.items[1:]
+
+		if !right.leaf() {
+			child.children = append(child.children, right.children[0])
+			right.children = right.children[1:]
+		}
+
+		return i
+	}
+
+	if i == len(n.items) {
+		i--
+	}
+
+	n.merge(i)
+	return i
+}
+
+// merge merges child i+1 and item i of the node into child i.
+func (n *btreeNode) merge(i int) {
+	left, right := n.children[i], n.children[i+1]
+
+	left.items = append(left.items, n.items[i])
+	left.items = append(left.items, right.items...)
+	left.children = append(left.children, right.children...)
+
+	n.items = append(n.items[:i], n.items[i+1:]...)
+	n.children = append(n.children[:i+1], n.children[i+2:]...)
+}
+
+func (n *btreeNode) min() *btreeItem {
+	for !n.leaf() {
+		n = n.children[0]
+	}
+
+	return n.items[0]
+}
+
+func (n *btreeNode) max() *btreeItem {
+	for !n.leaf() {
+		n = n.children[len(n.children)-1]
+	}
+
+	return n.items[len(n.items)-1]
+}
+
+func (n *btreeNode) ascend(
+	from interface{},
+	compare func(a, b interface{}) int,
+	fn func(*btreeItem) bool,
+) bool {
+	var i int
+	if from != nil {
+		i, _ = n.find(from, compare)
+	}
+
+	for ; i < len(n.items); i++ {
+		if !n.leaf() && !n.children[i].ascend(from, compare, fn) {
+			return false
+		}
+
+		if !fn(n.items[i]) {
+			return false
+		}
+	}
+
+	if n.leaf() {
+		return true
+	}
+
+	return n.children[len(n.items)].ascend(from, compare, fn)
+}
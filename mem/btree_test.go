@@ -0,0 +1,85 @@
+package mem
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compareInts(a, b interface{}) int {
+	return a.(int) - b.(int)
+}
+
+func btreeKeys(t *btree, from interface{}) []int {
+	var keys []int
+	t.Ascend(from, func(item *btreeItem) bool {
+		keys = append(keys, item.key.(int))
+		return true
+	})
+
+	return keys
+}
+
+func TestBTree(t *testing.T) {
+	assert := assert.New(t)
+
+	tree := newBTree(compareInts)
+	assert.Nil(tree.Get(1))
+	assert.False(tree.Delete(1))
+
+	const n = 1000
+	perm := rand.New(rand.NewSource(1)).Perm(n)
+	for _, k := range perm {
+		tree.GetOrInsert(k).value = k * 2
+	}
+
+	assert.Equal(n, tree.Len())
+	assert.Equal(n, len(btreeKeys(tree, nil)))
+
+	item := tree.GetOrInsert(7)
+	assert.Equal(14, item.value)
+	assert.Equal(n, tree.Len())
+
+	keys := btreeKeys(tree, 990)
+	assert.Equal([]int{990, 991, 992, 993, 994, 995, 996, 997, 998, 999}, keys)
+
+	for _, k := range perm {
+		if k%2 == 0 {
+			assert.True(tree.Delete(k))
+		}
+	}
+
+	assert.Equal(n/2, tree.Len())
+	assert.False(tree.Delete(2))
+	assert.Nil(tree.Get(2))
+	assert.Equal(6, tree.Get(3).value)
+
+	keys = btreeKeys(tree, nil)
+	assert.Len(keys, n/2)
+	for i, k := range keys {
+		assert.Equal(i*2+1, k)
+	}
+
+	for _, k := range perm {
+		tree.Delete(k)
+	}
+
+	assert.Equal(0, tree.Len())
+	assert.Empty(btreeKeys(tree, nil))
+}
+
+func TestBTree_AscendStop(t *testing.T) {
+	tree := newBTree(compareInts)
+	for i := 0; i < 100; i++ {
+		tree.GetOrInsert(i)
+	}
+
+	var keys []int
+	tree.Ascend(10, func(item *btreeItem) bool {
+		keys = append(keys, item.key.(int))
+		return item.key.(int) < 12
+	})
+
+	assert.Equal(t, []int{10, 11, 12}, keys)
+}
@@ -1,6 +1,10 @@
 package mem
 
-import "gopkg.in/sqle/sqle.v0/sql"
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
 
 type Database struct {
 	name   string
@@ -25,3 +29,23 @@ func (d *Database) Tables() map[string]sql.Table {
 func (d *Database) AddTable(name string, t *Table) {
 	d.tables[name] = t
 }
+
+// Create creates a new empty table with the given name and schema.
+func (d *Database) Create(name string, schema sql.Schema) error {
+	if _, ok := d.tables[name]; ok {
+		return fmt.Errorf("table already exists: %s", name)
+	}
+
+	d.tables[name] = NewTable(name, schema)
+	return nil
+}
+
+// Drop removes the table with the given name.
+func (d *Database) Drop(name string) error {
+	if _, ok := d.tables[name]; !ok {
+		return fmt.Errorf("table not found: %s", name)
+	}
+
+	delete(d.tables, name)
+	return nil
+}
@@ -19,7 +19,7 @@ func TestDatabase_AddTable(t *testing.T) {
 	db := NewDatabase("test")
 	tables := db.Tables()
 	assert.Equal(0, len(tables))
-	table := &Table{"test_table", sql.Schema{}, nil}
+	table := &Table{name: "test_table", schema: sql.Schema{}}
 	db.AddTable("test_table", table)
 	tables = db.Tables()
 	assert.Equal(1, len(tables))
@@ -27,3 +27,22 @@ func TestDatabase_AddTable(t *testing.T) {
 	assert.True(ok)
 	assert.NotNil(tt)
 }
+
+func TestDatabase_CreateDrop(t *testing.T) {
+	assert := assert.New(t)
+	db := NewDatabase("test")
+
+	schema := sql.Schema{{Name: "a", Type: sql.String}}
+	assert.Nil(db.Create("t", schema))
+	assert.Error(db.Create("t", schema))
+
+	table, ok := db.Tables()["t"]
+	assert.True(ok)
+	assert.Equal("t", table.Name())
+	assert.Equal("a", table.Schema()[0].Name)
+	assert.Equal("t", table.Schema()[0].Source)
+
+	assert.Nil(db.Drop("t"))
+	assert.Error(db.Drop("t"))
+	assert.Equal(0, len(db.Tables()))
+}
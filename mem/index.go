@@ -0,0 +1,159 @@
+package mem
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// index is a secondary index on a column of a Table. Rows with a NULL value
+// in the column are not indexed.
+type index interface {
+	definition() sql.Index
+	add(row sql.Row)
+	remove(row sql.Row)
+	lookup(r sql.IndexRange) ([]sql.Row, error)
+}
+
+// newIndex creates an empty index with the given definition on a table with
+// the given schema.
+func newIndex(def sql.Index, schema sql.Schema) (index, error) {
+	column := -1
+	for i, c := range schema {
+		if c.Name == def.Column {
+			column = i
+		}
+	}
+
+	if column < 0 {
+		return nil, fmt.Errorf("column not found: %s", def.Column)
+	}
+
+	switch def.Type {
+	case sql.HashIndex:
+		return &hashIndex{def, column, make(map[string][]sql.Row)}, nil
+	case sql.BTreeIndex:
+		typ := schema[column].Type
+		return &btreeIndex{def, column, typ, newBTree(typ.Compare)}, nil
+	default:
+		return nil, fmt.Errorf("unknown index type: %s", def.Type)
+	}
+}
+
+// hashIndex is an index with a map from the values of the column to the
+// rows with them.
+type hashIndex struct {
+	def    sql.Index
+	column int
+	rows   map[string][]sql.Row
+}
+
+func (i *hashIndex) definition() sql.Index {
+	return i.def
+}
+
+func (i *hashIndex) key(value interface{}) string {
+	return fmt.Sprintf("%#v", value)
+}
+
+func (i *hashIndex) add(row sql.Row) {
+	if v := row[i.column]; v != nil {
+		i.rows[i.key(v)] = append(i.rows[i.key(v)], row)
+	}
+}
+
+func (i *hashIndex) remove(row sql.Row) {
+	v := row[i.column]
+	if v == nil {
+		return
+	}
+
+	rows := removeRow(i.rows[i.key(v)], row)
+	if len(rows) == 0 {
+		delete(i.rows, i.key(v))
+	} else {
+		i.rows[i.key(v)] = rows
+	}
+}
+
+func (i *hashIndex) lookup(r sql.IndexRange) ([]sql.Row, error) {
+	if r.Lower == nil || !r.LowerInclusive || !r.UpperInclusive ||
+		!reflect.DeepEqual(r.Lower, r.Upper) {
+		return nil, fmt.Errorf("hash index %s only supports equality lookups", i.def.Name)
+	}
+
+	return append([]sql.Row(nil), i.rows[i.key(r.Lower)]...), nil
+}
+
+// btreeIndex is an index with a btree from the values of the column to the
+// rows with them.
+type btreeIndex struct {
+	def    sql.Index
+	column int
+	typ    sql.Type
+	tree   *btree
+}
+
+func (i *btreeIndex) definition() sql.Index {
+	return i.def
+}
+
+func (i *btreeIndex) add(row sql.Row) {
+	if v := row[i.column]; v != nil {
+		item := i.tree.GetOrInsert(v)
+		rows, _ := item.value.([]sql.Row)
+		item.value = append(rows, row)
+	}
+}
+
+func (i *btreeIndex) remove(row sql.Row) {
+	v := row[i.column]
+	if v == nil {
+		return
+	}
+
+	item := i.tree.Get(v)
+	if item == nil {
+		return
+	}
+
+	rows := removeRow(item.value.([]sql.Row), row)
+	if len(rows) == 0 {
+		i.tree.Delete(v)
+	} else {
+		item.value = rows
+	}
+}
+
+func (i *btreeIndex) lookup(r sql.IndexRange) ([]sql.Row, error) {
+	var rows []sql.Row
+	i.tree.Ascend(r.Lower, func(item *btreeItem) bool {
+		if r.Upper != nil {
+			cmp := i.typ.Compare(item.key, r.Upper)
+			if cmp > 0 || (cmp == 0 && !r.UpperInclusive) {
+				return false
+			}
+		}
+
+		if r.Lower != nil && !r.LowerInclusive && i.typ.Compare(item.key, r.Lower) == 0 {
+			return true
+		}
+
+		rows = append(rows, item.value.([]sql.Row)...)
+		return true
+	})
+
+	return rows, nil
+}
+
+// removeRow removes the first row equal to the given one from rows.
+func removeRow(rows []sql.Row, row sql.Row) []sql.Row {
+	for i, r := range rows {
+		if reflect.DeepEqual(r, row) {
+			return append(rows[:i], rows[i+1:]...)
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,105 @@
+package mem
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashIndex(t *testing.T) {
+	assert := assert.New(t)
+	schema := sql.Schema{
+		{Name: "a", Type: sql.Integer, Nullable: true},
+		{Name: "b", Type: sql.String},
+	}
+
+	idx, err := newIndex(sql.Index{Name: "idx", Column: "a", Type: sql.HashIndex}, schema)
+	assert.Nil(err)
+
+	idx.add(sql.NewRow(int32(1), "a"))
+	idx.add(sql.NewRow(int32(2), "b"))
+	idx.add(sql.NewRow(int32(1), "c"))
+	idx.add(sql.NewRow(nil, "d"))
+
+	rows, err := idx.lookup(sql.NewIndexPoint(int32(1)))
+	assert.Nil(err)
+	assert.Equal([]sql.Row{
+		sql.NewRow(int32(1), "a"),
+		sql.NewRow(int32(1), "c"),
+	}, rows)
+
+	idx.remove(sql.NewRow(int32(1), "a"))
+	rows, err = idx.lookup(sql.NewIndexPoint(int32(1)))
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow(int32(1), "c")}, rows)
+
+	rows, err = idx.lookup(sql.NewIndexPoint(int32(3)))
+	assert.Nil(err)
+	assert.Empty(rows)
+
+	_, err = idx.lookup(sql.IndexRange{Lower: int32(1)})
+	assert.Error(err)
+}
+
+func TestBTreeIndex(t *testing.T) {
+	assert := assert.New(t)
+	schema := sql.Schema{
+		{Name: "a", Type: sql.Integer, Nullable: true},
+		{Name: "b", Type: sql.String},
+	}
+
+	idx, err := newIndex(sql.Index{Name: "idx", Column: "a", Type: sql.BTreeIndex}, schema)
+	assert.Nil(err)
+
+	for i, s := range []string{"a", "b", "c", "d", "e"} {
+		idx.add(sql.NewRow(int32(5-i), s))
+	}
+	idx.add(sql.NewRow(int32(3), "f"))
+	idx.add(sql.NewRow(nil, "g"))
+
+	testCases := []struct {
+		name     string
+		r        sql.IndexRange
+		expected []string
+	}{
+		{"point", sql.NewIndexPoint(int32(3)), []string{"c", "f"}},
+		{"missing point", sql.NewIndexPoint(int32(7)), nil},
+		{"unbounded", sql.IndexRange{}, []string{"e", "d", "c", "f", "b", "a"}},
+		{"lower", sql.IndexRange{Lower: int32(4), LowerInclusive: true}, []string{"b", "a"}},
+		{"exclusive lower", sql.IndexRange{Lower: int32(4)}, []string{"a"}},
+		{"upper", sql.IndexRange{Upper: int32(2), UpperInclusive: true}, []string{"e", "d"}},
+		{"exclusive upper", sql.IndexRange{Upper: int32(2)}, []string{"e"}},
+		{"between", sql.IndexRange{Lower: int32(2), Upper: int32(4)}, []string{"c", "f"}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := idx.lookup(tt.r)
+			assert.Nil(err)
+
+			var values []string
+			for _, r := range rows {
+				values = append(values, r[1].(string))
+			}
+			assert.Equal(tt.expected, values)
+		})
+	}
+
+	idx.remove(sql.NewRow(int32(3), "c"))
+	idx.remove(sql.NewRow(int32(5), "a"))
+	rows, err := idx.lookup(sql.IndexRange{Lower: int32(3), LowerInclusive: true})
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow(int32(3), "f"), sql.NewRow(int32(4), "b")}, rows)
+}
+
+func TestNewIndex_Invalid(t *testing.T) {
+	schema := sql.Schema{{Name: "a", Type: sql.Integer}}
+
+	_, err := newIndex(sql.Index{Name: "idx", Column: "b", Type: sql.HashIndex}, schema)
+	assert.Error(t, err)
+
+	_, err = newIndex(sql.Index{Name: "idx", Column: "a", Type: "bitmap"}, schema)
+	assert.Error(t, err)
+}
@@ -1,21 +1,35 @@
 package mem
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"gopkg.in/sqle/sqle.v0/sql"
 )
 
 type Table struct {
-	name   string
-	schema sql.Schema
-	data   []sql.Row
+	name    string
+	schema  sql.Schema
+	data    []sql.Row
+	keys    []*uniqueKey
+	indexes []index
 }
 
+// NewTable creates a new Table with the given name and schema. The source of
+// the columns of the schema is set to the name of the table.
 func NewTable(name string, schema sql.Schema) *Table {
+	s := make(sql.Schema, len(schema))
+	for i, c := range schema {
+		nc := *c
+		nc.Source = name
+		s[i] = &nc
+	}
+
 	return &Table{
 		name:   name,
-		schema: schema,
+		schema: s,
+		keys:   newKeys(s),
 	}
 }
 
@@ -39,6 +53,79 @@ func (t *Table) RowIter() (sql.RowIter, error) {
 	return sql.RowsToRowIter(t.data...), nil
 }
 
+// HandledFilters returns all the filters, since the table can evaluate any
+// of them.
+func (t *Table) HandledFilters(filters []sql.Expression) []sql.Expression {
+	return filters
+}
+
+// WithFilters returns the rows matching all the filters, with only the
+// values of the given columns if columns is not nil.
+func (t *Table) WithFilters(columns []string, filters []sql.Expression) (sql.RowIter, error) {
+	var rows []sql.Row
+	for _, row := range t.data {
+		ok, err := matchesAll(row, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+
+	return t.project(columns, rows), nil
+}
+
+// WithProject returns the rows of the table with only the values of the
+// given columns, and nil for the rest.
+func (t *Table) WithProject(columns []string) (sql.RowIter, error) {
+	return t.project(columns, t.data), nil
+}
+
+func (t *Table) project(columns []string, rows []sql.Row) sql.RowIter {
+	if columns == nil {
+		return sql.RowsToRowIter(rows...)
+	}
+
+	var indexes []int
+	for _, c := range columns {
+		if idx := t.columnIndex(c); idx >= 0 {
+			indexes = append(indexes, idx)
+		}
+	}
+
+	projected := make([]sql.Row, len(rows))
+	for i, row := range rows {
+		projected[i] = make(sql.Row, len(row))
+		for _, idx := range indexes {
+			projected[i][idx] = row[idx]
+		}
+	}
+
+	return sql.RowsToRowIter(projected...)
+}
+
+func matchesAll(row sql.Row, filters []sql.Expression) (bool, error) {
+	for _, f := range filters {
+		v, err := f.Eval(row)
+		if err != nil {
+			return false, err
+		}
+
+		if v != true {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// EstimatedRowCount returns the number of rows in the table.
+func (t *Table) EstimatedRowCount() int64 {
+	return int64(len(t.data))
+}
+
 func (t *Table) TransformUp(f func(sql.Node) sql.Node) sql.Node {
 	return f(t)
 }
@@ -47,18 +134,477 @@ func (t *Table) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sq
 	return t
 }
 
+// ErrRowNotFound is returned when the row to update or delete is not in the
+// table.
+var ErrRowNotFound = errors.New("row not found")
+
 func (t *Table) Insert(row sql.Row) error {
+	if err := t.checkRow("insert", row); err != nil {
+		return err
+	}
+
+	if err := t.checkKeys(row); err != nil {
+		return err
+	}
+
+	row = row.Copy()
+	t.data = append(t.data, row)
+	t.indexRow(row)
+	return nil
+}
+
+// Update replaces each of the rows old of the table with the row in the same
+// position of new. Equal rows in old replace different rows of the table.
+// Keys are checked once all the rows are replaced, and nothing is changed if
+// any of the new rows is invalid or has a duplicate key.
+func (t *Table) Update(old, new []sql.Row) error {
+	if len(old) != len(new) {
+		return fmt.Errorf("update expected %d new rows, got %d", len(old), len(new))
+	}
+
+	for _, row := range new {
+		if err := t.checkRow("update", row); err != nil {
+			return err
+		}
+	}
+
+	positions, err := t.positions(old)
+	if err != nil {
+		return err
+	}
+
+	for _, pos := range positions {
+		t.unindexRow(t.data[pos])
+	}
+
+	// The rows are copied to a new slice instead of modified, since the old
+	// one may be in use by iterators created before.
+	data := make([]sql.Row, len(t.data))
+	copy(data, t.data)
+	for i, row := range new {
+		if err := t.checkKeys(row); err != nil {
+			for _, pos := range positions[:i] {
+				t.unindexRow(data[pos])
+			}
+
+			for _, pos := range positions {
+				t.indexRow(t.data[pos])
+			}
+
+			return err
+		}
+
+		data[positions[i]] = row.Copy()
+		t.indexRow(data[positions[i]])
+	}
+
+	t.data = data
+	return nil
+}
+
+// Delete removes the given rows from the table. Equal rows remove different
+// rows of the table.
+func (t *Table) Delete(rows []sql.Row) error {
+	positions, err := t.positions(rows)
+	if err != nil {
+		return err
+	}
+
+	deleted := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		deleted[pos] = true
+		t.unindexRow(t.data[pos])
+	}
+
+	// The rows are copied to a new slice instead of shifted, since the old
+	// one may be in use by iterators created before.
+	data := make([]sql.Row, 0, len(t.data)-len(positions))
+	for pos, row := range t.data {
+		if !deleted[pos] {
+			data = append(data, row)
+		}
+	}
+
+	t.data = data
+	return nil
+}
+
+// AddColumn appends a column to the table. Existing rows get the default
+// value of the column or, if it has none and is not nullable, the default
+// value of its type.
+func (t *Table) AddColumn(column *sql.Column) error {
+	if t.columnIndex(column.Name) >= 0 {
+		return fmt.Errorf("column already exists: %s", column.Name)
+	}
+
+	value := column.Default
+	if value == nil && !column.Nullable {
+		value = column.Type.Default()
+	}
+
+	c := *column
+	c.Source = t.name
+
+	// The schema and the rows are copied instead of modified, since they
+	// may be in use by plans or iterators created before.
+	schema := make(sql.Schema, len(t.schema), len(t.schema)+1)
+	copy(schema, t.schema)
+
+	data := make([]sql.Row, len(t.data))
+	for i, row := range t.data {
+		data[i] = append(row.Copy(), value)
+	}
+
+	return t.setSchema(append(schema, &c), data, t.Indexes())
+}
+
+// DropColumn removes the column with the given name.
+func (t *Table) DropColumn(name string) error {
+	idx := t.columnIndex(name)
+	if idx < 0 {
+		return fmt.Errorf("column not found: %s", name)
+	}
+
+	if len(t.schema) == 1 {
+		return fmt.Errorf("can't drop the only column of table %s", t.name)
+	}
+
+	schema := make(sql.Schema, 0, len(t.schema)-1)
+	schema = append(schema, t.schema[:idx]...)
+	schema = append(schema, t.schema[idx+1:]...)
+
+	data := make([]sql.Row, len(t.data))
+	for i, row := range t.data {
+		r := make(sql.Row, 0, len(row)-1)
+		r = append(r, row[:idx]...)
+		data[i] = append(r, row[idx+1:]...)
+	}
+
+	// Indexes on the dropped column are dropped too.
+	var indexes []sql.Index
+	for _, idx := range t.Indexes() {
+		if idx.Column != name {
+			indexes = append(indexes, idx)
+		}
+	}
+
+	return t.setSchema(schema, data, indexes)
+}
+
+// RenameColumn changes the name of a column.
+func (t *Table) RenameColumn(name, newName string) error {
+	idx := t.columnIndex(name)
+	if idx < 0 {
+		return fmt.Errorf("column not found: %s", name)
+	}
+
+	if t.columnIndex(newName) >= 0 {
+		return fmt.Errorf("column already exists: %s", newName)
+	}
+
+	schema := make(sql.Schema, len(t.schema))
+	copy(schema, t.schema)
+
+	c := *schema[idx]
+	c.Name = newName
+	schema[idx] = &c
+
+	indexes := t.Indexes()
+	for i := range indexes {
+		if indexes[i].Column == name {
+			indexes[i].Column = newName
+		}
+	}
+
+	return t.setSchema(schema, t.data, indexes)
+}
+
+// setSchema replaces the schema and rows of the table, rebuilding its keys
+// and the given indexes. Nothing is changed if the rows violate any of the
+// new keys.
+func (t *Table) setSchema(schema sql.Schema, data []sql.Row, defs []sql.Index) error {
+	keys := newKeys(schema)
+	indexes := make([]index, len(defs))
+	for i, def := range defs {
+		idx, err := newIndex(def, schema)
+		if err != nil {
+			return err
+		}
+
+		indexes[i] = idx
+	}
+
+	for _, row := range data {
+		for _, k := range keys {
+			if err := k.add(row); err != nil {
+				return err
+			}
+		}
+
+		for _, idx := range indexes {
+			idx.add(row)
+		}
+	}
+
+	t.schema = schema
+	t.data = data
+	t.keys = keys
+	t.indexes = indexes
+	return nil
+}
+
+// CreateIndex creates an index on a column of the table.
+func (t *Table) CreateIndex(def sql.Index) error {
+	if t.findIndex(def.Name) != nil {
+		return fmt.Errorf("index already exists: %s", def.Name)
+	}
+
+	idx, err := newIndex(def, t.schema)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range t.data {
+		idx.add(row)
+	}
+
+	t.indexes = append(t.indexes, idx)
+	return nil
+}
+
+// Indexes returns the indexes of the table.
+func (t *Table) Indexes() []sql.Index {
+	defs := make([]sql.Index, len(t.indexes))
+	for i, idx := range t.indexes {
+		defs[i] = idx.definition()
+	}
+
+	return defs
+}
+
+// IndexLookup returns the rows whose value for the column of the given index
+// is in the range. The bounds of the range are converted to the type of the
+// column.
+func (t *Table) IndexLookup(name string, r sql.IndexRange) (sql.RowIter, error) {
+	idx := t.findIndex(name)
+	if idx == nil {
+		return nil, fmt.Errorf("index not found: %s", name)
+	}
+
+	typ := t.schema[t.columnIndex(idx.definition().Column)].Type
+
+	var err error
+	if r.Lower != nil {
+		if r.Lower, err = typ.Convert(r.Lower); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Upper != nil {
+		if r.Upper, err = typ.Convert(r.Upper); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := idx.lookup(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+func (t *Table) findIndex(name string) index {
+	for _, idx := range t.indexes {
+		if idx.definition().Name == name {
+			return idx
+		}
+	}
+
+	return nil
+}
+
+func (t *Table) columnIndex(name string) int {
+	for i, c := range t.schema {
+		if c.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// positions returns the position in the table of each of the rows. Equal rows
+// get the positions of different rows of the table.
+func (t *Table) positions(rows []sql.Row) ([]int, error) {
+	pending := make(map[string][]int, len(rows))
+	for i, row := range rows {
+		key := rowKey(row)
+		pending[key] = append(pending[key], i)
+	}
+
+	positions := make([]int, len(rows))
+	found := 0
+	for pos, row := range t.data {
+		if found == len(rows) {
+			break
+		}
+
+		key := rowKey(row)
+		idxs := pending[key]
+		if len(idxs) == 0 {
+			continue
+		}
+
+		positions[idxs[0]] = pos
+		pending[key] = idxs[1:]
+		found++
+	}
+
+	if found < len(rows) {
+		return nil, ErrRowNotFound
+	}
+
+	return positions, nil
+}
+
+// rowKey returns a string that is equal for rows with equal values.
+func rowKey(row sql.Row) string {
+	return fmt.Sprintf("%#v", row)
+}
+
+func (t *Table) checkRow(op string, row sql.Row) error {
 	if len(row) != len(t.schema) {
-		return fmt.Errorf("insert expected %d values, got %d", len(t.schema), len(row))
+		return fmt.Errorf("%s expected %d values, got %d", op, len(t.schema), len(row))
 	}
 
 	for idx, value := range row {
 		c := t.schema[idx]
+		if value == nil && !c.Nullable {
+			return fmt.Errorf("%w in column %s", sql.ErrNotNullable, c.Name)
+		}
+
 		if !c.Check(value) {
-			return sql.ErrInvalidType
+			return fmt.Errorf("%w in column %s", sql.ErrInvalidType, c.Name)
+		}
+	}
+
+	return nil
+}
+
+// checkKeys returns a *sql.DuplicateKeyError if row has the same key as a
+// row in the table.
+func (t *Table) checkKeys(row sql.Row) error {
+	for _, k := range t.keys {
+		if existing, ok := k.get(row); ok {
+			return &sql.DuplicateKeyError{Key: k.name, Existing: existing.Copy()}
+		}
+	}
+
+	return nil
+}
+
+// indexRow adds the row to the keys and indexes of the table. It must have
+// been checked with checkKeys.
+func (t *Table) indexRow(row sql.Row) {
+	for _, k := range t.keys {
+		_ = k.add(row)
+	}
+
+	for _, idx := range t.indexes {
+		idx.add(row)
+	}
+}
+
+func (t *Table) unindexRow(row sql.Row) {
+	for _, k := range t.keys {
+		k.remove(row)
+	}
+
+	for _, idx := range t.indexes {
+		idx.remove(row)
+	}
+}
+
+// primaryKey is the name of the key made of the primary key columns.
+const primaryKey = "PRIMARY"
+
+// uniqueKey indexes the rows of a table by the values of the columns of a
+// primary key or unique column. Rows with NULL values in any of the columns
+// are not indexed, since they never collide.
+type uniqueKey struct {
+	name    string
+	columns []int
+	rows    map[string]sql.Row
+}
+
+// newKeys returns the keys defined by the schema: one for the primary key
+// columns, if any, and one named after each unique column.
+func newKeys(schema sql.Schema) []*uniqueKey {
+	var keys []*uniqueKey
+	var primary []int
+	for i, c := range schema {
+		if c.PrimaryKey {
+			primary = append(primary, i)
+		}
+	}
+
+	if len(primary) > 0 {
+		keys = append(keys, newUniqueKey(primaryKey, primary...))
+	}
+
+	for i, c := range schema {
+		if c.Unique && !(len(primary) == 1 && primary[0] == i) {
+			keys = append(keys, newUniqueKey(c.Name, i))
+		}
+	}
+
+	return keys
+}
+
+func newUniqueKey(name string, columns ...int) *uniqueKey {
+	return &uniqueKey{name, columns, make(map[string]sql.Row)}
+}
+
+func (k *uniqueKey) key(row sql.Row) (string, bool) {
+	values := make([]string, len(k.columns))
+	for i, idx := range k.columns {
+		if row[idx] == nil {
+			return "", false
 		}
+
+		values[i] = fmt.Sprintf("%#v", row[idx])
 	}
 
-	t.data = append(t.data, row.Copy())
+	return strings.Join(values, ","), true
+}
+
+func (k *uniqueKey) get(row sql.Row) (sql.Row, bool) {
+	key, ok := k.key(row)
+	if !ok {
+		return nil, false
+	}
+
+	existing, ok := k.rows[key]
+	return existing, ok
+}
+
+func (k *uniqueKey) add(row sql.Row) error {
+	key, ok := k.key(row)
+	if !ok {
+		return nil
+	}
+
+	if existing, ok := k.rows[key]; ok {
+		return &sql.DuplicateKeyError{Key: k.name, Existing: existing.Copy()}
+	}
+
+	k.rows[key] = row
 	return nil
 }
+
+func (k *uniqueKey) remove(row sql.Row) {
+	if key, ok := k.key(row); ok {
+		delete(k.rows, key)
+	}
+}
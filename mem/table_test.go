@@ -1,9 +1,11 @@
 package mem
 
 import (
+	"errors"
 	"testing"
 
 	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,16 +13,31 @@ import (
 func TestTable_Name(t *testing.T) {
 	assert := assert.New(t)
 	s := sql.Schema{
-		{"col1", sql.String, nil, true},
+		{Name: "col1", Type: sql.String, Nullable: true},
 	}
 	table := NewTable("test", s)
 	assert.Equal("test", table.Name())
 }
 
+func TestTable_Schema(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "col1", Type: sql.String, Nullable: true},
+	}
+
+	table := NewTable("test", s)
+	assert.Equal(sql.Schema{
+		{Name: "col1", Type: sql.String, Nullable: true, Source: "test"},
+	}, table.Schema())
+
+	// the given schema must not be modified
+	assert.Equal("", s[0].Source)
+}
+
 func TestTable_Insert_RowIter(t *testing.T) {
 	assert := assert.New(t)
 	s := sql.Schema{
-		{"col1", sql.String, nil, true},
+		{Name: "col1", Type: sql.String, Nullable: true},
 	}
 
 	table := NewTable("test", s)
@@ -42,3 +59,402 @@ func TestTable_Insert_RowIter(t *testing.T) {
 	assert.Nil(s.CheckRow(rows[0]))
 	assert.Nil(s.CheckRow(rows[1]))
 }
+
+func TestTable_EstimatedRowCount(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "col1", Type: sql.String, Nullable: true},
+	}
+
+	table := NewTable("test", s)
+	assert.Equal(int64(0), table.EstimatedRowCount())
+
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+	assert.Nil(table.Insert(sql.NewRow("bar")))
+	assert.Equal(int64(2), table.EstimatedRowCount())
+}
+
+func TestTable_Update(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "col1", Type: sql.String},
+	}
+
+	table := NewTable("test", s)
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+	assert.Nil(table.Insert(sql.NewRow("bar")))
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+
+	assert.Nil(table.Update([]sql.Row{sql.NewRow("foo")}, []sql.Row{sql.NewRow("baz")}))
+	assert.Equal(ErrRowNotFound, table.Update([]sql.Row{sql.NewRow("qux")}, []sql.Row{sql.NewRow("baz")}))
+	assert.True(errors.Is(table.Update([]sql.Row{sql.NewRow("bar")}, []sql.Row{sql.NewRow(1)}), sql.ErrInvalidType))
+	assert.Error(table.Update([]sql.Row{sql.NewRow("bar")}, []sql.Row{sql.NewRow("a", "b")}))
+
+	rows, err := sql.NodeToRows(table)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{
+		sql.NewRow("baz"),
+		sql.NewRow("bar"),
+		sql.NewRow("foo"),
+	}, rows)
+}
+
+func TestTable_Update_Batch(t *testing.T) {
+	assert := assert.New(t)
+
+	table := NewTable("test", sql.Schema{
+		{Name: "a", Type: sql.Integer, PrimaryKey: true},
+		{Name: "b", Type: sql.String},
+	})
+	assert.Nil(table.Insert(sql.NewRow(int32(1), "x")))
+	assert.Nil(table.Insert(sql.NewRow(int32(2), "x")))
+	assert.Nil(table.Insert(sql.NewRow(int32(3), "x")))
+
+	all := func() []sql.Row {
+		rows, err := sql.NodeToRows(table)
+		assert.Nil(err)
+		return rows
+	}
+
+	// keys are checked once all the rows are replaced
+	assert.Nil(table.Update(
+		[]sql.Row{sql.NewRow(int32(1), "x"), sql.NewRow(int32(2), "x"), sql.NewRow(int32(3), "x")},
+		[]sql.Row{sql.NewRow(int32(2), "x"), sql.NewRow(int32(3), "x"), sql.NewRow(int32(4), "x")},
+	))
+	assert.Equal([]sql.Row{
+		sql.NewRow(int32(2), "x"),
+		sql.NewRow(int32(3), "x"),
+		sql.NewRow(int32(4), "x"),
+	}, all())
+
+	// nothing is changed if any of the new rows has a duplicate key
+	var dup *sql.DuplicateKeyError
+	err := table.Update(
+		[]sql.Row{sql.NewRow(int32(2), "x"), sql.NewRow(int32(3), "x")},
+		[]sql.Row{sql.NewRow(int32(5), "y"), sql.NewRow(int32(4), "y")},
+	)
+	assert.True(errors.As(err, &dup))
+	assert.Equal([]sql.Row{
+		sql.NewRow(int32(2), "x"),
+		sql.NewRow(int32(3), "x"),
+		sql.NewRow(int32(4), "x"),
+	}, all())
+	assert.Nil(table.Insert(sql.NewRow(int32(5), "z")))
+	assert.Error(table.Insert(sql.NewRow(int32(2), "z")))
+
+	// equal rows replace different rows of the table
+	table = NewTable("test", sql.Schema{{Name: "a", Type: sql.Integer}})
+	assert.Nil(table.Insert(sql.NewRow(int32(1))))
+	assert.Nil(table.Insert(sql.NewRow(int32(1))))
+	assert.Nil(table.Update(
+		[]sql.Row{sql.NewRow(int32(1)), sql.NewRow(int32(1))},
+		[]sql.Row{sql.NewRow(int32(2)), sql.NewRow(int32(3))},
+	))
+	assert.Equal([]sql.Row{sql.NewRow(int32(2)), sql.NewRow(int32(3))}, all())
+}
+
+func TestTable_Update_OpenIterator(t *testing.T) {
+	assert := assert.New(t)
+
+	table := NewTable("test", sql.Schema{{Name: "col1", Type: sql.Integer}})
+	for i := int32(1); i <= 3; i++ {
+		assert.Nil(table.Insert(sql.NewRow(i)))
+	}
+
+	iter, err := table.RowIter()
+	assert.Nil(err)
+
+	// updating the rows being read doesn't change what an open iterator
+	// returns
+	assert.Nil(table.Update([]sql.Row{sql.NewRow(int32(2))}, []sql.Row{sql.NewRow(int32(20))}))
+
+	rows, err := sql.RowIterToRows(iter)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{
+		sql.NewRow(int32(1)),
+		sql.NewRow(int32(2)),
+		sql.NewRow(int32(3)),
+	}, rows)
+}
+
+func TestTable_Delete(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "col1", Type: sql.String},
+	}
+
+	table := NewTable("test", s)
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+	assert.Nil(table.Insert(sql.NewRow("bar")))
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+
+	assert.Nil(table.Delete([]sql.Row{sql.NewRow("foo")}))
+	assert.Equal(ErrRowNotFound, table.Delete([]sql.Row{sql.NewRow("qux")}))
+
+	rows, err := sql.NodeToRows(table)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{
+		sql.NewRow("bar"),
+		sql.NewRow("foo"),
+	}, rows)
+
+	// equal rows remove different rows of the table, and nothing is removed
+	// if any of them is not in the table
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+	err = table.Delete([]sql.Row{sql.NewRow("foo"), sql.NewRow("foo"), sql.NewRow("foo")})
+	assert.Equal(ErrRowNotFound, err)
+	assert.Equal(int64(3), table.EstimatedRowCount())
+
+	assert.Nil(table.Delete([]sql.Row{sql.NewRow("foo"), sql.NewRow("foo")}))
+	rows, err = sql.NodeToRows(table)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow("bar")}, rows)
+}
+
+func TestTable_Delete_OpenIterator(t *testing.T) {
+	assert := assert.New(t)
+
+	table := NewTable("test", sql.Schema{{Name: "col1", Type: sql.Integer}})
+	for i := int32(1); i <= 4; i++ {
+		assert.Nil(table.Insert(sql.NewRow(i)))
+	}
+
+	iter, err := table.RowIter()
+	assert.Nil(err)
+
+	// deleting the rows being read doesn't change what an open iterator
+	// returns
+	row, err := iter.Next()
+	assert.Nil(err)
+	assert.Nil(table.Delete([]sql.Row{row}))
+	assert.Nil(table.Delete([]sql.Row{sql.NewRow(int32(2))}))
+
+	rows, err := sql.RowIterToRows(iter)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{
+		sql.NewRow(int32(2)),
+		sql.NewRow(int32(3)),
+		sql.NewRow(int32(4)),
+	}, rows)
+}
+
+func TestTable_AlterColumns(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "col1", Type: sql.String},
+	}
+
+	table := NewTable("test", s)
+	assert.Nil(table.Insert(sql.NewRow("foo")))
+
+	iter, err := table.RowIter()
+	assert.Nil(err)
+
+	assert.Nil(table.AddColumn(&sql.Column{Name: "col2", Type: sql.BigInteger, Default: int64(5)}))
+	assert.Nil(table.AddColumn(&sql.Column{Name: "col3", Type: sql.String, Nullable: true}))
+	assert.Nil(table.AddColumn(&sql.Column{Name: "col4", Type: sql.BigInteger}))
+	assert.Error(table.AddColumn(&sql.Column{Name: "col1", Type: sql.String}))
+
+	assert.Equal("test", table.Schema()[3].Source)
+	assert.Equal(1, len(s))
+
+	rows, err := sql.NodeToRows(table)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow("foo", int64(5), nil, int64(0))}, rows)
+
+	// iterators created before are not affected
+	rows, err = sql.RowIterToRows(iter)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow("foo")}, rows)
+
+	assert.Nil(table.DropColumn("col3"))
+	assert.Error(table.DropColumn("col3"))
+
+	assert.Nil(table.RenameColumn("col4", "col3"))
+	assert.Error(table.RenameColumn("col4", "col5"))
+	assert.Error(table.RenameColumn("col1", "col2"))
+
+	assert.Equal("col3", table.Schema()[2].Name)
+	assert.Nil(table.Insert(sql.NewRow("bar", int64(1), int64(2))))
+
+	rows, err = sql.NodeToRows(table)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{
+		sql.NewRow("foo", int64(5), int64(0)),
+		sql.NewRow("bar", int64(1), int64(2)),
+	}, rows)
+
+	assert.Nil(table.DropColumn("col1"))
+	assert.Nil(table.DropColumn("col2"))
+	assert.Error(table.DropColumn("col3"))
+}
+
+func TestTable_Insert_NotNullable(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "col1", Type: sql.String, Nullable: true},
+		{Name: "col2", Type: sql.String},
+	}
+
+	table := NewTable("test", s)
+	assert.Nil(table.Insert(sql.NewRow(nil, "foo")))
+
+	err := table.Insert(sql.NewRow("foo", nil))
+	assert.True(errors.Is(err, sql.ErrNotNullable))
+	assert.Contains(err.Error(), "col2")
+
+	err = table.Insert(sql.NewRow(1, "foo"))
+	assert.True(errors.Is(err, sql.ErrInvalidType))
+	assert.Contains(err.Error(), "col1")
+}
+
+func TestTable_Keys(t *testing.T) {
+	assert := assert.New(t)
+	s := sql.Schema{
+		{Name: "a", Type: sql.Integer, PrimaryKey: true},
+		{Name: "b", Type: sql.String, PrimaryKey: true},
+		{Name: "c", Type: sql.String, Nullable: true, Unique: true},
+	}
+
+	table := NewTable("test", s)
+	assert.Nil(table.Insert(sql.NewRow(int32(1), "foo", "x")))
+	assert.Nil(table.Insert(sql.NewRow(int32(1), "bar", nil)))
+	assert.Nil(table.Insert(sql.NewRow(int32(2), "foo", nil)))
+
+	var dup *sql.DuplicateKeyError
+	err := table.Insert(sql.NewRow(int32(1), "bar", "y"))
+	assert.True(errors.As(err, &dup))
+	assert.Equal("PRIMARY", dup.Key)
+	assert.Equal(sql.NewRow(int32(1), "bar", nil), dup.Existing)
+
+	err = table.Insert(sql.NewRow(int32(3), "baz", "x"))
+	assert.True(errors.As(err, &dup))
+	assert.Equal("c", dup.Key)
+	assert.Equal(sql.NewRow(int32(1), "foo", "x"), dup.Existing)
+
+	// a row can be updated keeping its own key
+	assert.Nil(table.Update(
+		[]sql.Row{sql.NewRow(int32(1), "foo", "x")},
+		[]sql.Row{sql.NewRow(int32(1), "foo", "z")},
+	))
+
+	err = table.Update(
+		[]sql.Row{sql.NewRow(int32(2), "foo", nil)},
+		[]sql.Row{sql.NewRow(int32(1), "foo", nil)},
+	)
+	assert.True(errors.As(err, &dup))
+	assert.Equal("PRIMARY", dup.Key)
+
+	// deleted keys can be used again
+	assert.Nil(table.Delete([]sql.Row{sql.NewRow(int32(1), "foo", "z")}))
+	assert.Nil(table.Insert(sql.NewRow(int32(1), "foo", "z")))
+	assert.Equal(int64(3), table.EstimatedRowCount())
+
+	// keys are rebuilt when the columns change
+	assert.Nil(table.RenameColumn("c", "d"))
+	err = table.Insert(sql.NewRow(int32(4), "foo", "z"))
+	assert.True(errors.As(err, &dup))
+	assert.Equal("d", dup.Key)
+
+	err = table.AddColumn(&sql.Column{Name: "e", Type: sql.Integer, Unique: true})
+	assert.True(errors.As(err, &dup))
+	assert.Equal("e", dup.Key)
+	assert.Len(table.Schema(), 3)
+
+	assert.Nil(table.DropColumn("d"))
+	assert.Nil(table.Insert(sql.NewRow(int32(4), "foo")))
+}
+
+func TestTable_Indexes(t *testing.T) {
+	assert := assert.New(t)
+	table := NewTable("test", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+
+	assert.Nil(table.Insert(sql.NewRow(int32(1), "a")))
+	assert.Nil(table.Insert(sql.NewRow(int32(2), "b")))
+
+	assert.Nil(table.CreateIndex(sql.Index{Name: "a_idx", Column: "a", Type: sql.BTreeIndex}))
+	assert.Nil(table.CreateIndex(sql.Index{Name: "b_idx", Column: "b", Type: sql.HashIndex}))
+	assert.Error(table.CreateIndex(sql.Index{Name: "a_idx", Column: "b", Type: sql.HashIndex}))
+	assert.Equal([]sql.Index{
+		{Name: "a_idx", Column: "a", Type: sql.BTreeIndex},
+		{Name: "b_idx", Column: "b", Type: sql.HashIndex},
+	}, table.Indexes())
+
+	assert.Nil(table.Insert(sql.NewRow(int32(3), "a")))
+	assert.Nil(table.Update([]sql.Row{sql.NewRow(int32(2), "b")}, []sql.Row{sql.NewRow(int32(2), "a")}))
+	assert.Nil(table.Delete([]sql.Row{sql.NewRow(int32(1), "a")}))
+
+	lookup := func(index string, r sql.IndexRange) []sql.Row {
+		iter, err := table.IndexLookup(index, r)
+		assert.Nil(err)
+		rows, err := sql.RowIterToRows(iter)
+		assert.Nil(err)
+		return rows
+	}
+
+	// bounds are converted to the type of the column
+	assert.Equal([]sql.Row{sql.NewRow(int32(2), "a"), sql.NewRow(int32(3), "a")},
+		lookup("a_idx", sql.IndexRange{Lower: int64(2), LowerInclusive: true}))
+	// updated rows are added again to the indexes
+	assert.Equal([]sql.Row{sql.NewRow(int32(3), "a"), sql.NewRow(int32(2), "a")},
+		lookup("b_idx", sql.NewIndexPoint("a")))
+
+	_, err := table.IndexLookup("c_idx", sql.NewIndexPoint("a"))
+	assert.Error(err)
+
+	// indexes follow the changes of the columns
+	assert.Nil(table.RenameColumn("b", "c"))
+	assert.Equal("c", table.Indexes()[1].Column)
+	assert.Nil(table.DropColumn("a"))
+	assert.Equal([]sql.Index{{Name: "b_idx", Column: "c", Type: sql.HashIndex}}, table.Indexes())
+	assert.Equal([]sql.Row{sql.NewRow("a"), sql.NewRow("a")},
+		lookup("b_idx", sql.NewIndexPoint("a")))
+}
+
+func TestTable_Pushdown(t *testing.T) {
+	assert := assert.New(t)
+	table := NewTable("test", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+
+	assert.Nil(table.Insert(sql.NewRow(int32(1), "a")))
+	assert.Nil(table.Insert(sql.NewRow(int32(2), "b")))
+
+	filters := []sql.Expression{
+		expression.NewEquals(
+			expression.NewGetField(1, sql.String, "b", false),
+			expression.NewLiteral("b", sql.String),
+		),
+	}
+	assert.Equal(filters, table.HandledFilters(filters))
+
+	iter, err := table.WithFilters(nil, filters)
+	assert.Nil(err)
+	rows, err := sql.RowIterToRows(iter)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow(int32(2), "b")}, rows)
+
+	iter, err = table.WithFilters([]string{"a"}, filters)
+	assert.Nil(err)
+	rows, err = sql.RowIterToRows(iter)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow(int32(2), nil)}, rows)
+
+	iter, err = table.WithProject([]string{"b"})
+	assert.Nil(err)
+	rows, err = sql.RowIterToRows(iter)
+	assert.Nil(err)
+	assert.Equal([]sql.Row{sql.NewRow(nil, "a"), sql.NewRow(nil, "b")}, rows)
+
+	_, err = table.WithFilters(nil, []sql.Expression{
+		expression.NewEquals(
+			expression.NewGetField(1, sql.String, "b", false),
+			expression.NewPlaceholder("v1"),
+		),
+	})
+	assert.Error(err)
+}
@@ -40,7 +40,7 @@ func (a *Analyzer) Analyze(n sql.Node) (sql.Node, error) {
 	i := 0
 	for !reflect.DeepEqual(prev, cur) {
 		prev = cur
-		cur = a.analyzeOnce(n)
+		cur = a.analyzeOnce(cur)
 		i++
 		if i >= maxAnalysisIterations {
 			return cur, fmt.Errorf("exceeded max analysis iterations (%d)", maxAnalysisIterations)
@@ -63,14 +63,15 @@ func (a *Analyzer) analyzeOnce(n sql.Node) sql.Node {
 	return result
 }
 
+// validate returns the errors of the validation rules for the node and its
+// children. Errors of the children go first, as the errors of a node are
+// often caused by them.
 func (a *Analyzer) validate(n sql.Node) (validationErrors []error) {
-	validationErrors = append(validationErrors, a.validateOnce(n)...)
-
 	for _, node := range n.Children() {
 		validationErrors = append(validationErrors, a.validate(node)...)
 	}
 
-	return validationErrors
+	return append(validationErrors, a.validateOnce(n)...)
 }
 
 func (a *Analyzer) validateOnce(n sql.Node) (validationErrors []error) {
@@ -53,7 +53,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(notAnalyzed)
 	var expected sql.Node = plan.NewProject(
-		[]sql.Expression{expression.NewGetField(0, sql.Integer, "i", false)},
+		[]sql.Expression{expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)},
 		table,
 	)
 	assert.NoError(err)
@@ -73,7 +73,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(notAnalyzed)
 	expected = plan.NewProject(
-		[]sql.Expression{expression.NewGetField(0, sql.Integer, "i", false)},
+		[]sql.Expression{expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)},
 		table,
 	)
 	assert.NoError(err)
@@ -88,9 +88,9 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(notAnalyzed)
 	expected = plan.NewProject(
-		[]sql.Expression{expression.NewGetField(0, sql.Integer, "i", false)},
+		[]sql.Expression{expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)},
 		plan.NewProject(
-			[]sql.Expression{expression.NewGetField(0, sql.Integer, "i", false)},
+			[]sql.Expression{expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)},
 			table,
 		),
 	)
@@ -110,7 +110,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	expected = plan.NewProject(
 		[]sql.Expression{
 			expression.NewAlias(
-				expression.NewGetField(0, sql.Integer, "i", false),
+				expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false),
 				"foo",
 			),
 		},
@@ -131,12 +131,13 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	)
 	analyzed, err = a.Analyze(notAnalyzed)
 	expected = plan.NewProject(
-		[]sql.Expression{expression.NewGetField(0, sql.Integer, "i", false)},
-		plan.NewFilter(
-			expression.NewEquals(
-				expression.NewGetField(0, sql.Integer, "i", false),
+		[]sql.Expression{expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)},
+		plan.NewPushdownTable(
+			nil,
+			[]sql.Expression{expression.NewEquals(
+				expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false),
 				expression.NewLiteral(int32(1), sql.Integer),
-			),
+			)},
 			table,
 		),
 	)
@@ -156,8 +157,8 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	analyzed, err = a.Analyze(notAnalyzed)
 	expected = plan.NewProject(
 		[]sql.Expression{
-			expression.NewGetField(0, sql.Integer, "i", false),
-			expression.NewGetField(1, sql.Integer, "i2", false),
+			expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false),
+			expression.NewGetFieldWithTable(1, sql.Integer, "mytable2", "i2", false),
 		},
 		plan.NewCrossJoin(table, table2),
 	)
@@ -176,7 +177,7 @@ func TestAnalyzer_Analyze(t *testing.T) {
 	expected = plan.NewLimit(int64(1),
 		plan.NewProject(
 			[]sql.Expression{
-				expression.NewGetField(0, sql.Integer, "i", false),
+				expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false),
 			},
 			table,
 		),
@@ -206,3 +207,79 @@ func TestAnalyzer_Analyze_MaxIterations(t *testing.T) {
 	assert.NotNil(err)
 	assert.Equal(plan.NewUnresolvedTable("table1001"), analyzed)
 }
+
+func TestAnalyzer_Analyze_Having(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("mytable", sql.Schema{
+		{Name: "i", Type: sql.Integer},
+		{Name: "s", Type: sql.String},
+	})
+	db := mem.NewDatabase("mydb")
+	db.AddTable("mytable", table)
+
+	catalog := sql.NewCatalog()
+	catalog.Databases = []sql.Database{db}
+	require.NoError(expression.RegisterDefaults(catalog))
+	a := analyzer.New(catalog)
+	a.CurrentDatabase = "mydb"
+
+	s := expression.NewGetFieldWithTable(1, sql.String, "mytable", "s", false)
+	i := expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)
+
+	// aggregations of the select list are reused and the rest are computed
+	// by the GroupBy and projected away
+	notAnalyzed := plan.NewHaving(
+		expression.NewAnd(
+			expression.NewGreaterThan(
+				expression.NewUnresolvedFunction("count", true, expression.NewStar()),
+				expression.NewLiteral(int32(1), sql.Integer),
+			),
+			expression.NewEquals(
+				expression.NewUnresolvedFunction("first", true,
+					expression.NewUnresolvedColumn("i")),
+				expression.NewLiteral(int32(1), sql.Integer),
+			),
+		),
+		plan.NewGroupBy(
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("s"),
+				expression.NewUnresolvedFunction("count", true, expression.NewStar()),
+			},
+			[]sql.Expression{expression.NewUnresolvedColumn("s")},
+			plan.NewUnresolvedTable("mytable"),
+		),
+	)
+
+	expected := plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetField(0, sql.String, "s", false),
+			expression.NewGetField(1, sql.Integer, "count(*)", false),
+		},
+		plan.NewHaving(
+			expression.NewAnd(
+				expression.NewGreaterThan(
+					expression.NewGetField(1, sql.Integer, "count(*)", false),
+					expression.NewLiteral(int32(1), sql.Integer),
+				),
+				expression.NewEquals(
+					expression.NewGetField(2, sql.Integer, "first(i)", false),
+					expression.NewLiteral(int32(1), sql.Integer),
+				),
+			),
+			plan.NewGroupBy(
+				[]sql.Expression{
+					s,
+					expression.NewCount(expression.NewStar()),
+					expression.NewFirst(i),
+				},
+				[]sql.Expression{s},
+				table,
+			),
+		),
+	)
+
+	analyzed, err := a.Analyze(notAnalyzed)
+	require.NoError(err)
+	require.Equal(expected, analyzed)
+}
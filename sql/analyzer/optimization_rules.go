@@ -0,0 +1,834 @@
+package analyzer
+
+import (
+	"math"
+	"reflect"
+	"sort"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+	"gopkg.in/sqle/sqle.v0/sql/plan"
+)
+
+// unknownRowCount is the estimated number of rows of nodes that can't
+// estimate it, so they are joined after the ones that can.
+const unknownRowCount = math.MaxInt64
+
+// reorderJoins reorders the inputs of filtered trees of cross joins so the
+// ones with fewer estimated rows are joined first. Since the order of the
+// columns changes, the fields of the filter are updated and a projection
+// restoring the original order is added on top.
+func reorderJoins(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		filter, ok := n.(*plan.Filter)
+		if !ok || !filter.Resolved() {
+			return n
+		}
+
+		cj, ok := filter.Child.(*plan.CrossJoin)
+		if !ok {
+			return n
+		}
+
+		joins, mapping, ok := reorderCrossJoins(cj)
+		if !ok {
+			return n
+		}
+
+		cond := remapFields(filter.Expression, mapping)
+		return restoreColumnOrder(plan.NewFilter(cond, joins), cj.Schema(), mapping)
+	})
+}
+
+// reorderCrossJoins builds a left-deep tree of cross joins with the inputs
+// of the given tree sorted by their estimated number of rows. It also returns
+// the new index of each of the original columns. It returns false if the
+// order of the inputs does not change.
+func reorderCrossJoins(cj *plan.CrossJoin) (sql.Node, []int, bool) {
+	leaves := crossJoinLeaves(cj)
+
+	offsets := make(map[sql.Node]int, len(leaves))
+	var offset int
+	for _, l := range leaves {
+		offsets[l] = offset
+		offset += len(l.Schema())
+	}
+
+	sorted := append([]sql.Node{}, leaves...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return estimateRowCount(sorted[i]) < estimateRowCount(sorted[j])
+	})
+
+	changed := false
+	for i := range leaves {
+		if leaves[i] != sorted[i] {
+			changed = true
+			break
+		}
+	}
+
+	if !changed {
+		return nil, nil, false
+	}
+
+	mapping := make([]int, offset)
+	var node sql.Node
+	offset = 0
+	for _, l := range sorted {
+		width := len(l.Schema())
+		for i := 0; i < width; i++ {
+			mapping[offsets[l]+i] = offset + i
+		}
+		offset += width
+
+		if node == nil {
+			node = l
+		} else {
+			node = plan.NewCrossJoin(node, l)
+		}
+	}
+
+	return node, mapping, true
+}
+
+func crossJoinLeaves(n sql.Node) []sql.Node {
+	cj, ok := n.(*plan.CrossJoin)
+	if !ok {
+		return []sql.Node{n}
+	}
+
+	return append(crossJoinLeaves(cj.Left), crossJoinLeaves(cj.Right)...)
+}
+
+func estimateRowCount(n sql.Node) int64 {
+	if e, ok := n.(sql.RowCountEstimator); ok {
+		return e.EstimatedRowCount()
+	}
+
+	children := n.Children()
+	if len(children) == 1 {
+		return estimateRowCount(children[0])
+	}
+
+	return unknownRowCount
+}
+
+func remapFields(e sql.Expression, mapping []int) sql.Expression {
+	return e.TransformUp(func(e sql.Expression) sql.Expression {
+		gf, ok := e.(*expression.GetField)
+		if !ok {
+			return e
+		}
+
+		return expression.NewGetFieldWithTable(mapping[gf.Index()], gf.Type(),
+			gf.Table(), gf.Name(), gf.IsNullable())
+	})
+}
+
+func restoreColumnOrder(n sql.Node, schema sql.Schema, mapping []int) sql.Node {
+	exprs := make([]sql.Expression, len(schema))
+	for i, c := range schema {
+		exprs[i] = expression.NewGetFieldWithTable(mapping[i], c.Type, c.Source,
+			c.Name, c.Nullable)
+	}
+
+	return plan.NewProject(exprs, n)
+}
+
+// hashJoins replaces inner joins and filters over cross joins that have an
+// equality condition between both sides with a hash join. The rest of the
+// condition, if any, is kept as a filter over the hash join.
+func hashJoins(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		if !n.Resolved() {
+			return n
+		}
+
+		switch node := n.(type) {
+		case *plan.Filter:
+			cj, ok := node.Child.(*plan.CrossJoin)
+			if !ok {
+				return n
+			}
+
+			return toHashJoin(n, cj.Left, cj.Right, node.Expression)
+		case *plan.InnerJoin:
+			return toHashJoin(n, node.Left, node.Right, node.Cond)
+		default:
+			return n
+		}
+	})
+}
+
+func toHashJoin(n, left, right sql.Node, cond sql.Expression) sql.Node {
+	width := len(left.Schema())
+	conds := splitConjunction(cond)
+	for i, c := range conds {
+		eq, ok := c.(*expression.Equals)
+		if !ok {
+			continue
+		}
+
+		lkey, rkey, ok := joinKeys(eq, width)
+		if !ok {
+			continue
+		}
+
+		// The rest of the conditions are pushed down to the side they refer
+		// to, so the inputs of the join are as small as possible.
+		var leftConds, rightConds, rest []sql.Expression
+		for j, c := range conds {
+			if j == i {
+				continue
+			}
+
+			switch fieldsSide(c, width) {
+			case sideLeft:
+				leftConds = append(leftConds, c)
+			case sideRight:
+				rightConds = append(rightConds, shiftFields(c, -width))
+			default:
+				rest = append(rest, c)
+			}
+		}
+
+		if len(leftConds) > 0 {
+			left = plan.NewFilter(joinConjunction(leftConds), left)
+		}
+
+		if len(rightConds) > 0 {
+			right = plan.NewFilter(joinConjunction(rightConds), right)
+		}
+
+		var node sql.Node = plan.NewHashJoin(left, right, lkey, rkey)
+		if len(rest) > 0 {
+			node = plan.NewFilter(joinConjunction(rest), node)
+		}
+
+		return node
+	}
+
+	return n
+}
+
+// joinKeys returns the expressions of an equality that must be evaluated on
+// each side of a join whose left side has the given number of columns. The
+// key of the right side is rewritten to be evaluated against rows of the
+// right side only.
+func joinKeys(eq *expression.Equals, width int) (sql.Expression, sql.Expression, bool) {
+	l, r := eq.Left, eq.Right
+	ls := fieldsSide(l, width)
+	rs := fieldsSide(r, width)
+
+	if ls == sideRight && rs == sideLeft {
+		l, r = r, l
+		ls, rs = rs, ls
+	}
+
+	if ls != sideLeft || rs != sideRight {
+		return nil, nil, false
+	}
+
+	return l, shiftFields(r, -width), true
+}
+
+type side byte
+
+const (
+	sideNone side = iota
+	sideLeft
+	sideRight
+	sideBoth
+)
+
+// fieldsSide returns the side of the join the fields used in the expression
+// belong to.
+func fieldsSide(e sql.Expression, width int) side {
+	s := sideNone
+	e.TransformUp(func(e sql.Expression) sql.Expression {
+		gf, ok := e.(*expression.GetField)
+		if !ok {
+			return e
+		}
+
+		fs := sideLeft
+		if gf.Index() >= width {
+			fs = sideRight
+		}
+
+		if s == sideNone {
+			s = fs
+		} else if s != fs {
+			s = sideBoth
+		}
+
+		return e
+	})
+
+	return s
+}
+
+func shiftFields(e sql.Expression, offset int) sql.Expression {
+	return e.TransformUp(func(e sql.Expression) sql.Expression {
+		gf, ok := e.(*expression.GetField)
+		if !ok {
+			return e
+		}
+
+		return expression.NewGetFieldWithTable(gf.Index()+offset, gf.Type(),
+			gf.Table(), gf.Name(), gf.IsNullable())
+	})
+}
+
+// splitConjunction returns the expressions that are joined with AND in the
+// given expression.
+func splitConjunction(e sql.Expression) []sql.Expression {
+	and, ok := e.(*expression.And)
+	if !ok {
+		return []sql.Expression{e}
+	}
+
+	return append(splitConjunction(and.Left), splitConjunction(and.Right)...)
+}
+
+// joinConjunction joins the given expressions with AND.
+func joinConjunction(exprs []sql.Expression) sql.Expression {
+	result := exprs[0]
+	for _, e := range exprs[1:] {
+		result = expression.NewAnd(result, e)
+	}
+
+	return result
+}
+
+// indexLookups replaces filters over indexable tables that compare an indexed
+// column with a literal with a lookup on the index. Conditions with equality
+// are preferred, since they return fewer rows. The conditions not used by
+// the lookup are kept in a filter over it.
+func indexLookups(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		filter, ok := n.(*plan.Filter)
+		if !ok || !filter.Resolved() {
+			return n
+		}
+
+		child := filter.Child
+		alias, isAlias := child.(*plan.TableAlias)
+		if isAlias {
+			child = alias.Child
+		}
+
+		table, ok := child.(sql.IndexableTable)
+		if !ok {
+			return n
+		}
+
+		index, r, rest, ok := indexLookup(table, splitConjunction(filter.Expression))
+		if !ok {
+			return n
+		}
+
+		var node sql.Node = plan.NewIndexLookup(index, r, table)
+		if isAlias {
+			node = plan.NewTableAlias(alias.Name(), node)
+		}
+
+		if len(rest) > 0 {
+			node = plan.NewFilter(joinConjunction(rest), node)
+		}
+
+		return node
+	})
+}
+
+// indexLookup returns the index and range to look up the rows of the table
+// matching the conditions, and the conditions not covered by the range.
+func indexLookup(
+	table sql.IndexableTable,
+	conds []sql.Expression,
+) (string, sql.IndexRange, []sql.Expression, bool) {
+	schema := table.Schema()
+	var (
+		rangeIndex string
+		rangeUsed  []int
+		rangeR     sql.IndexRange
+	)
+
+	for _, index := range table.Indexes() {
+		column := -1
+		for i, c := range schema {
+			if c.Name == index.Column {
+				column = i
+			}
+		}
+
+		if column < 0 {
+			continue
+		}
+
+		typ := schema[column].Type
+		var used []int
+		var r sql.IndexRange
+		for i, c := range conds {
+			op, v, ok := columnComparison(c, column, typ)
+			if !ok {
+				continue
+			}
+
+			if op == "=" {
+				return index.Name, sql.NewIndexPoint(v), without(conds, i), true
+			}
+
+			if index.Type != sql.BTreeIndex {
+				continue
+			}
+
+			switch op {
+			case ">", ">=":
+				cmp := 1
+				if r.Lower != nil {
+					cmp = typ.Compare(v, r.Lower)
+				}
+
+				if cmp > 0 || (cmp == 0 && op == ">") {
+					r.Lower, r.LowerInclusive = v, op == ">="
+				}
+			case "<", "<=":
+				cmp := -1
+				if r.Upper != nil {
+					cmp = typ.Compare(v, r.Upper)
+				}
+
+				if cmp < 0 || (cmp == 0 && op == "<") {
+					r.Upper, r.UpperInclusive = v, op == "<="
+				}
+			}
+
+			used = append(used, i)
+		}
+
+		if rangeUsed == nil && used != nil {
+			rangeIndex, rangeUsed, rangeR = index.Name, used, r
+		}
+	}
+
+	if rangeUsed == nil {
+		return "", sql.IndexRange{}, nil, false
+	}
+
+	return rangeIndex, rangeR, without(conds, rangeUsed...), true
+}
+
+// columnComparison returns the operator and the value of a comparison between
+// the column with the given index and a literal, with the column on the left
+// side, so 1 < a is returned as > and 1. The value is converted to the type
+// of the column, and the comparison is not returned if it's NULL or the
+// conversion loses information.
+func columnComparison(e sql.Expression, column int, typ sql.Type) (string, interface{}, bool) {
+	var op string
+	var left, right sql.Expression
+	switch e := e.(type) {
+	case *expression.Equals:
+		op, left, right = "=", e.Left, e.Right
+	case *expression.LessThan:
+		op, left, right = "<", e.Left, e.Right
+	case *expression.LessThanOrEqual:
+		op, left, right = "<=", e.Left, e.Right
+	case *expression.GreaterThan:
+		op, left, right = ">", e.Left, e.Right
+	case *expression.GreaterThanOrEqual:
+		op, left, right = ">=", e.Left, e.Right
+	default:
+		return "", nil, false
+	}
+
+	if _, ok := left.(*expression.Literal); ok {
+		left, right = right, left
+		op = flippedOperators[op]
+	}
+
+	gf, ok := left.(*expression.GetField)
+	if !ok || gf.Index() != column {
+		return "", nil, false
+	}
+
+	lit, ok := right.(*expression.Literal)
+	if !ok {
+		return "", nil, false
+	}
+
+	value, err := lit.Eval(nil)
+	if err != nil || value == nil {
+		return "", nil, false
+	}
+
+	v, err := typ.Convert(value)
+	if err != nil {
+		return "", nil, false
+	}
+
+	back, err := lit.Type().Convert(v)
+	if err != nil || lit.Type().Compare(back, value) != 0 {
+		return "", nil, false
+	}
+
+	return op, v, true
+}
+
+var flippedOperators = map[string]string{
+	"=":  "=",
+	"<":  ">",
+	"<=": ">=",
+	">":  "<",
+	">=": "<=",
+}
+
+// without returns the expressions but the ones at the given indexes.
+func without(exprs []sql.Expression, indexes ...int) []sql.Expression {
+	var result []sql.Expression
+	for i, e := range exprs {
+		skip := false
+		for _, idx := range indexes {
+			if i == idx {
+				skip = true
+			}
+		}
+
+		if !skip {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// pushdown moves the conditions of filters over tables implementing
+// sql.FilteredTable into the tables, and makes tables implementing
+// sql.ProjectedTable read only the columns used by the query, if they don't
+// use all of them. The tables of nodes modifying data read all the columns,
+// since the whole rows are needed to modify them.
+func pushdown(a *Analyzer, n sql.Node) sql.Node {
+	if !n.Resolved() {
+		return n
+	}
+
+	// Describe only reads the schema of its child, so it's left as is.
+	var columns []string
+	switch n.(type) {
+	case sql.Executor, *plan.Describe:
+	default:
+		columns = usedColumns(n)
+	}
+
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		switch node := n.(type) {
+		case *plan.Filter:
+			return pushdownFilters(node)
+		case *plan.PushdownTable:
+			// Tables already pushed down are wrapped again every time the
+			// rule is applied.
+			if inner, ok := node.Child.(*plan.PushdownTable); ok {
+				return plan.NewPushdownTable(inner.Columns, node.Filters, inner.Child)
+			}
+		case *plan.IndexLookup:
+			// Index lookups read the rows from the indexes of the table.
+			if pt, ok := node.Child.(*plan.PushdownTable); ok {
+				return plan.NewIndexLookup(node.Index, node.Range, pt.Child)
+			}
+		case sql.ProjectedTable:
+			if columns == nil {
+				return n
+			}
+
+			if tc := tableColumns(node, columns); len(tc) < len(node.Schema()) {
+				return plan.NewPushdownTable(tc, nil, node)
+			}
+		}
+
+		return n
+	})
+}
+
+// pushdownFilters moves the conditions of a filter handled by the table
+// below it into the table.
+func pushdownFilters(filter *plan.Filter) sql.Node {
+	child := filter.Child
+	alias, isAlias := child.(*plan.TableAlias)
+	if isAlias {
+		child = alias.Child
+	}
+
+	var columns []string
+	var filters []sql.Expression
+	if pt, ok := child.(*plan.PushdownTable); ok {
+		child, columns, filters = pt.Child, pt.Columns, pt.Filters
+	}
+
+	table, ok := child.(sql.FilteredTable)
+	if !ok {
+		return filter
+	}
+
+	conds := splitConjunction(filter.Expression)
+	handled := table.HandledFilters(conds)
+	if len(handled) == 0 {
+		return filter
+	}
+
+	var rest []sql.Expression
+	for _, c := range conds {
+		if !containsExpression(handled, c) {
+			rest = append(rest, c)
+		}
+	}
+
+	filters = append(append([]sql.Expression{}, filters...), handled...)
+
+	var node sql.Node = plan.NewPushdownTable(columns, filters, table)
+	if isAlias {
+		node = plan.NewTableAlias(alias.Name(), node)
+	}
+
+	if len(rest) > 0 {
+		node = plan.NewFilter(joinConjunction(rest), node)
+	}
+
+	return node
+}
+
+func containsExpression(exprs []sql.Expression, e sql.Expression) bool {
+	for _, expr := range exprs {
+		if reflect.DeepEqual(expr, e) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// usedColumns returns the names of the columns used by the expressions of
+// the node and its children, and the ones returned by the node.
+func usedColumns(n sql.Node) []string {
+	columns := []string{}
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			columns = append(columns, name)
+		}
+	}
+
+	for _, c := range n.Schema() {
+		add(c.Name)
+	}
+
+	n.TransformExpressionsUp(func(e sql.Expression) sql.Expression {
+		if gf, ok := e.(*expression.GetField); ok {
+			add(gf.Name())
+		}
+
+		return e
+	})
+
+	return columns
+}
+
+// tableColumns returns the names of the columns of the table among the
+// given ones, in the order of the schema.
+func tableColumns(table sql.Table, columns []string) []string {
+	result := []string{}
+	for _, c := range table.Schema() {
+		for _, name := range columns {
+			if c.Name == name {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// foldConstants replaces the deterministic expressions whose children are
+// all literals with the literal of their value, and simplifies boolean
+// expressions with literals, such as x AND true, and double negations.
+// Filters that are always true are removed and the ones that are never true
+// are replaced with an EmptyResult. The expressions returned by projections
+// and groupings keep their names with an alias.
+func foldConstants(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		if !n.Resolved() {
+			return n
+		}
+
+		switch node := n.(type) {
+		case *plan.Project:
+			return plan.NewProject(foldNamedExpressions(node.Expressions), node.Child)
+		case *plan.GroupBy:
+			return plan.NewGroupBy(
+				foldNamedExpressions(node.Aggregate),
+				foldNamedExpressions(node.Grouping),
+				node.Child,
+			)
+		case *plan.Filter:
+			cond := node.Expression.TransformUp(foldExpression)
+			if lit, ok := cond.(*expression.Literal); ok {
+				if v, _ := lit.Eval(nil); v == true {
+					return node.Child
+				}
+
+				return plan.NewEmptyResult(node.Child)
+			}
+
+			return plan.NewFilter(cond, node.Child)
+		default:
+			return n.TransformExpressionsUp(foldExpression)
+		}
+	})
+}
+
+func foldNamedExpressions(exprs []sql.Expression) []sql.Expression {
+	var result []sql.Expression
+	for _, e := range exprs {
+		folded := e.TransformUp(foldExpression)
+		if _, ok := e.(*expression.Alias); !ok && !reflect.DeepEqual(e, folded) {
+			folded = expression.NewAlias(folded, e.Name())
+		}
+
+		result = append(result, folded)
+	}
+
+	return result
+}
+
+// foldExpression folds or simplifies the given expression, whose children
+// have already been folded.
+func foldExpression(e sql.Expression) sql.Expression {
+	if isDeterministic(e) && childrenAreLiterals(e) {
+		v, err := e.Eval(nil)
+		if err != nil {
+			// The error is returned when the query is executed.
+			return e
+		}
+
+		return expression.NewLiteral(v, e.Type())
+	}
+
+	switch e := e.(type) {
+	case *expression.And:
+		for _, pair := range [][2]sql.Expression{{e.Left, e.Right}, {e.Right, e.Left}} {
+			switch v, ok := literalValue(pair[0]); {
+			case ok && v == false:
+				return expression.NewLiteral(false, sql.Boolean)
+			case ok && v == true && pair[1].Type() == sql.Boolean:
+				return pair[1]
+			}
+		}
+	case *expression.Or:
+		for _, pair := range [][2]sql.Expression{{e.Left, e.Right}, {e.Right, e.Left}} {
+			switch v, ok := literalValue(pair[0]); {
+			case ok && v == true:
+				return expression.NewLiteral(true, sql.Boolean)
+			case ok && v == false && pair[1].Type() == sql.Boolean:
+				return pair[1]
+			}
+		}
+	case *expression.Not:
+		if not, ok := e.Child.(*expression.Not); ok && not.Child.Type() == sql.Boolean {
+			return not.Child
+		}
+	}
+
+	return e
+}
+
+// isDeterministic returns whether the expression always has the same value
+// for the same values of its children. Unknown expressions, such as
+// functions, are not.
+func isDeterministic(e sql.Expression) bool {
+	switch e.(type) {
+	case *expression.Equals, *expression.Regexp,
+		*expression.GreaterThan, *expression.GreaterThanOrEqual,
+		*expression.LessThan, *expression.LessThanOrEqual,
+		*expression.And, *expression.Or, *expression.Not,
+		*expression.Arithmetic, *expression.UnaryMinus, *expression.IsNull:
+		return true
+	default:
+		return false
+	}
+}
+
+// childrenAreLiterals returns whether all the expressions below the given
+// one are literals.
+func childrenAreLiterals(e sql.Expression) bool {
+	var others int
+	e.TransformUp(func(e sql.Expression) sql.Expression {
+		if _, ok := e.(*expression.Literal); !ok {
+			others++
+		}
+
+		return e
+	})
+
+	return others == 1
+}
+
+func literalValue(e sql.Expression) (interface{}, bool) {
+	lit, ok := e.(*expression.Literal)
+	if !ok {
+		return nil, false
+	}
+
+	v, _ := lit.Eval(nil)
+	return v, true
+}
+
+// topN replaces a sort below a limit with a TopN node, which only keeps in
+// memory the rows that may be returned. Between the limit and the sort there
+// can be offsets, whose skipped rows are kept too, and projections, which
+// don't change the number or the order of the rows. A limit right above the
+// sort is replaced along with it.
+func topN(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		limit, ok := n.(*plan.Limit)
+		if !ok || !limit.Resolved() {
+			return n
+		}
+
+		if sort, ok := limit.Child.(*plan.Sort); ok {
+			return plan.NewTopN(sort.SortFields, limit.RowCount(), sort.Child)
+		}
+
+		child, ok := withTopN(limit.Child, limit.RowCount())
+		if !ok {
+			return n
+		}
+
+		return plan.NewLimitExpression(limit.RowCount(), child)
+	})
+}
+
+// withTopN replaces the sort below the node, if any, with a TopN returning
+// the given number of rows.
+func withTopN(n sql.Node, rows sql.Expression) (sql.Node, bool) {
+	switch node := n.(type) {
+	case *plan.Sort:
+		return plan.NewTopN(node.SortFields, rows, node.Child), true
+	case *plan.Offset:
+		c, ok := withTopN(node.Child, expression.NewPlus(rows, node.RowCount()))
+		if !ok {
+			return n, false
+		}
+
+		return plan.NewOffsetExpression(node.RowCount(), c), true
+	case *plan.Project:
+		c, ok := withTopN(node.Child, rows)
+		if !ok {
+			return n, false
+		}
+
+		return plan.NewProject(node.Expressions, c), true
+	default:
+		return n, false
+	}
+}
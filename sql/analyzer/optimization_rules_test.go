@@ -0,0 +1,474 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+	"gopkg.in/sqle/sqle.v0/sql/plan"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_hashJoins(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("hash_joins")
+
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "c", Type: sql.Integer},
+		{Name: "d", Type: sql.String},
+	})
+
+	notAnalyzed := plan.NewFilter(
+		expression.NewAnd(
+			expression.NewEquals(
+				expression.NewGetField(2, sql.Integer, "c", false),
+				expression.NewGetField(0, sql.Integer, "a", false),
+			),
+			expression.NewEquals(
+				expression.NewGetField(1, sql.String, "b", false),
+				expression.NewLiteral("foo", sql.String),
+			),
+		),
+		plan.NewCrossJoin(left, right),
+	)
+
+	expected := plan.NewHashJoin(
+		plan.NewFilter(
+			expression.NewEquals(
+				expression.NewGetField(1, sql.String, "b", false),
+				expression.NewLiteral("foo", sql.String),
+			),
+			left,
+		),
+		right,
+		expression.NewGetField(0, sql.Integer, "a", false),
+		expression.NewGetField(0, sql.Integer, "c", false),
+	)
+
+	require.Equal(expected, f.Apply(nil, notAnalyzed))
+
+	// conditions on the right side are shifted and the ones on both sides
+	// are kept above the join
+	notAnalyzed = plan.NewFilter(
+		expression.NewAnd(
+			expression.NewAnd(
+				expression.NewEquals(
+					expression.NewGetField(0, sql.Integer, "a", false),
+					expression.NewGetField(2, sql.Integer, "c", false),
+				),
+				expression.NewEquals(
+					expression.NewGetField(3, sql.String, "d", false),
+					expression.NewLiteral("foo", sql.String),
+				),
+			),
+			expression.NewNot(expression.NewEquals(
+				expression.NewGetField(1, sql.String, "b", false),
+				expression.NewGetField(3, sql.String, "d", false),
+			)),
+		),
+		plan.NewCrossJoin(left, right),
+	)
+
+	expected2 := plan.NewFilter(
+		expression.NewNot(expression.NewEquals(
+			expression.NewGetField(1, sql.String, "b", false),
+			expression.NewGetField(3, sql.String, "d", false),
+		)),
+		plan.NewHashJoin(
+			left,
+			plan.NewFilter(
+				expression.NewEquals(
+					expression.NewGetField(1, sql.String, "d", false),
+					expression.NewLiteral("foo", sql.String),
+				),
+				right,
+			),
+			expression.NewGetField(0, sql.Integer, "a", false),
+			expression.NewGetField(0, sql.Integer, "c", false),
+		),
+	)
+
+	require.Equal(expected2, f.Apply(nil, notAnalyzed))
+
+	analyzed := f.Apply(nil, plan.NewInnerJoin(left, right,
+		expression.NewEquals(
+			expression.NewGetField(1, sql.String, "b", false),
+			expression.NewGetField(3, sql.String, "d", false),
+		),
+	))
+	require.Equal(plan.NewHashJoin(left, right,
+		expression.NewGetField(1, sql.String, "b", false),
+		expression.NewGetField(1, sql.String, "d", false),
+	), analyzed)
+
+	// conditions not comparing both sides are left untouched
+	notAnalyzed = plan.NewFilter(
+		expression.NewEquals(
+			expression.NewGetField(0, sql.Integer, "a", false),
+			expression.NewLiteral(int32(1), sql.Integer),
+		),
+		plan.NewCrossJoin(left, right),
+	)
+	require.Equal(notAnalyzed, f.Apply(nil, notAnalyzed))
+}
+
+func Test_reorderJoins(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("reorder_joins")
+
+	big := mem.NewTable("big", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+	small := mem.NewTable("small", sql.Schema{
+		{Name: "c", Type: sql.Integer},
+	})
+
+	require.NoError(big.Insert(sql.NewRow(int32(1), "one")))
+	require.NoError(big.Insert(sql.NewRow(int32(2), "two")))
+	require.NoError(small.Insert(sql.NewRow(int32(1))))
+
+	notAnalyzed := plan.NewFilter(
+		expression.NewEquals(
+			expression.NewGetField(0, sql.Integer, "a", false),
+			expression.NewGetField(2, sql.Integer, "c", false),
+		),
+		plan.NewCrossJoin(big, small),
+	)
+
+	expected := plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetFieldWithTable(1, sql.Integer, "big", "a", false),
+			expression.NewGetFieldWithTable(2, sql.String, "big", "b", false),
+			expression.NewGetFieldWithTable(0, sql.Integer, "small", "c", false),
+		},
+		plan.NewFilter(
+			expression.NewEquals(
+				expression.NewGetField(1, sql.Integer, "a", false),
+				expression.NewGetField(0, sql.Integer, "c", false),
+			),
+			plan.NewCrossJoin(small, big),
+		),
+	)
+
+	require.Equal(expected, f.Apply(nil, notAnalyzed))
+
+	// joins already in order are left untouched
+	ordered := plan.NewFilter(
+		expression.NewEquals(
+			expression.NewGetField(0, sql.Integer, "c", false),
+			expression.NewGetField(1, sql.Integer, "a", false),
+		),
+		plan.NewCrossJoin(small, big),
+	)
+	require.Equal(ordered, f.Apply(nil, ordered))
+}
+
+func Test_indexLookups(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("index_lookups")
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+		{Name: "c", Type: sql.String},
+	})
+	require.NoError(table.CreateIndex(sql.Index{Name: "a_idx", Column: "a", Type: sql.BTreeIndex}))
+	require.NoError(table.CreateIndex(sql.Index{Name: "b_idx", Column: "b", Type: sql.HashIndex}))
+
+	a := expression.NewGetFieldWithTable(0, sql.Integer, "t", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.String, "t", "b", false)
+	c := expression.NewGetFieldWithTable(2, sql.String, "t", "c", false)
+
+	// equalities are preferred to ranges
+	notAnalyzed := plan.NewFilter(
+		expression.NewAnd(
+			expression.NewAnd(
+				expression.NewGreaterThan(a, expression.NewLiteral(int64(1), sql.BigInteger)),
+				expression.NewEquals(expression.NewLiteral("foo", sql.String), b),
+			),
+			expression.NewEquals(c, expression.NewLiteral("bar", sql.String)),
+		),
+		table,
+	)
+
+	expected := plan.NewFilter(
+		expression.NewAnd(
+			expression.NewGreaterThan(a, expression.NewLiteral(int64(1), sql.BigInteger)),
+			expression.NewEquals(c, expression.NewLiteral("bar", sql.String)),
+		),
+		plan.NewIndexLookup("b_idx", sql.NewIndexPoint("foo"), table),
+	)
+	require.Equal(expected, f.Apply(nil, notAnalyzed))
+
+	// ranges are merged and hash indexes are not used for them
+	notAnalyzed = plan.NewFilter(
+		expression.NewAnd(
+			expression.NewAnd(
+				expression.NewLessThanOrEqual(expression.NewLiteral(int64(1), sql.BigInteger), a),
+				expression.NewGreaterThan(a, expression.NewLiteral(int64(1), sql.BigInteger)),
+			),
+			expression.NewAnd(
+				expression.NewLessThan(a, expression.NewLiteral(int64(5), sql.BigInteger)),
+				expression.NewGreaterThan(b, expression.NewLiteral("foo", sql.String)),
+			),
+		),
+		plan.NewTableAlias("x", table),
+	)
+
+	expected = plan.NewFilter(
+		expression.NewGreaterThan(b, expression.NewLiteral("foo", sql.String)),
+		plan.NewTableAlias("x", plan.NewIndexLookup("a_idx", sql.IndexRange{
+			Lower: int32(1),
+			Upper: int32(5),
+		}, table)),
+	)
+	require.Equal(expected, f.Apply(nil, notAnalyzed))
+
+	// the whole filter can be replaced
+	notAnalyzed = plan.NewFilter(
+		expression.NewEquals(a, expression.NewLiteral(int64(3), sql.BigInteger)),
+		table,
+	)
+	require.Equal(
+		plan.NewIndexLookup("a_idx", sql.NewIndexPoint(int32(3)), table),
+		f.Apply(nil, notAnalyzed),
+	)
+
+	// comparisons that can't use an index are left untouched
+	for _, cond := range []sql.Expression{
+		expression.NewEquals(c, expression.NewLiteral("foo", sql.String)),
+		expression.NewEquals(a, expression.NewLiteral(1.5, sql.Float)),
+		expression.NewEquals(a, expression.NewLiteral(nil, sql.Null)),
+		expression.NewEquals(a, b),
+		expression.NewLessThan(b, expression.NewLiteral("foo", sql.String)),
+		expression.NewNot(expression.NewEquals(a, expression.NewLiteral(int64(1), sql.BigInteger))),
+	} {
+		notAnalyzed = plan.NewFilter(cond, table)
+		require.Equal(notAnalyzed, f.Apply(nil, notAnalyzed))
+	}
+}
+
+func Test_pushdown(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("pushdown")
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+		{Name: "c", Type: sql.String},
+	})
+
+	a := expression.NewGetFieldWithTable(0, sql.Integer, "t", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.String, "t", "b", false)
+	cond := expression.NewEquals(b, expression.NewLiteral("foo", sql.String))
+
+	notAnalyzed := plan.NewProject(
+		[]sql.Expression{a},
+		plan.NewFilter(cond, plan.NewTableAlias("x", table)),
+	)
+	expected := plan.NewProject(
+		[]sql.Expression{a},
+		plan.NewTableAlias("x", plan.NewPushdownTable(
+			[]string{"a", "b"},
+			[]sql.Expression{cond},
+			table,
+		)),
+	)
+
+	analyzed := f.Apply(nil, notAnalyzed)
+	require.Equal(expected, analyzed)
+
+	// applying the rule again changes nothing
+	require.Equal(expected, f.Apply(nil, analyzed))
+
+	// tables of nodes modifying data read all the columns
+	notAnalyzed2 := plan.NewUpdate(
+		[]plan.UpdateField{{Column: a, Value: expression.NewLiteral(int32(1), sql.Integer)}},
+		plan.NewFilter(cond, table),
+	)
+	require.Equal(plan.NewUpdate(
+		[]plan.UpdateField{{Column: a, Value: expression.NewLiteral(int32(1), sql.Integer)}},
+		plan.NewPushdownTable(nil, []sql.Expression{cond}, table),
+	), f.Apply(nil, notAnalyzed2))
+
+	// tables are not projected if all their columns are used, nor under
+	// index lookups
+	for _, n := range []sql.Node{
+		plan.NewProject(
+			[]sql.Expression{a, b, expression.NewGetFieldWithTable(2, sql.String, "t", "c", false)},
+			table,
+		),
+		plan.NewProject(
+			[]sql.Expression{a},
+			plan.NewIndexLookup("idx", sql.NewIndexPoint(int32(1)), table),
+		),
+		plan.NewDescribe(table),
+	} {
+		require.Equal(n, f.Apply(nil, n))
+	}
+}
+
+func Test_foldConstants(t *testing.T) {
+	f := getRule("fold_constants")
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.Boolean},
+	})
+
+	a := expression.NewGetFieldWithTable(0, sql.Integer, "t", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.Boolean, "t", "b", false)
+	lit := func(v interface{}, typ sql.Type) sql.Expression {
+		return expression.NewLiteral(v, typ)
+	}
+	one := lit(int64(1), sql.BigInteger)
+	two := lit(int64(2), sql.BigInteger)
+
+	testCases := []struct {
+		name     string
+		node     sql.Node
+		expected sql.Node
+	}{
+		{
+			"arithmetic in a filter",
+			plan.NewFilter(
+				expression.NewGreaterThan(a, expression.NewPlus(one, two)),
+				table,
+			),
+			plan.NewFilter(
+				expression.NewGreaterThan(a, lit(int64(3), sql.BigInteger)),
+				table,
+			),
+		},
+		{
+			"always true filter",
+			plan.NewFilter(expression.NewEquals(one, one), table),
+			table,
+		},
+		{
+			"always false filter",
+			plan.NewFilter(
+				expression.NewAnd(b, expression.NewEquals(one, two)),
+				table,
+			),
+			plan.NewEmptyResult(table),
+		},
+		{
+			"NULL filter",
+			plan.NewFilter(expression.NewEquals(a, lit(nil, sql.Null)), table),
+			plan.NewFilter(expression.NewEquals(a, lit(nil, sql.Null)), table),
+		},
+		{
+			"double negation and boolean simplification",
+			plan.NewFilter(
+				expression.NewOr(
+					expression.NewNot(expression.NewNot(b)),
+					expression.NewEquals(one, two),
+				),
+				table,
+			),
+			plan.NewFilter(b, table),
+		},
+		{
+			"projection names",
+			plan.NewProject(
+				[]sql.Expression{
+					expression.NewMult(two, two),
+					expression.NewAlias(expression.NewMinus(two, one), "x"),
+					expression.NewPlus(a, one),
+				},
+				table,
+			),
+			plan.NewProject(
+				[]sql.Expression{
+					expression.NewAlias(lit(int64(4), sql.BigInteger), "literal_biginteger * literal_biginteger"),
+					expression.NewAlias(lit(int64(1), sql.BigInteger), "x"),
+					expression.NewPlus(a, one),
+				},
+				table,
+			),
+		},
+		{
+			"aggregations are not folded",
+			plan.NewGroupBy(
+				[]sql.Expression{expression.NewCount(one)},
+				nil,
+				table,
+			),
+			plan.NewGroupBy(
+				[]sql.Expression{expression.NewCount(one)},
+				nil,
+				table,
+			),
+		},
+		{
+			"other nodes",
+			plan.NewSort(
+				[]plan.SortField{{Column: expression.NewUnaryMinus(one)}},
+				table,
+			),
+			plan.NewSort(
+				[]plan.SortField{{Column: lit(int64(-1), sql.BigInteger)}},
+				table,
+			),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, f.Apply(nil, tt.node))
+		})
+	}
+}
+
+func Test_topN(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("top_n")
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+
+	a := expression.NewGetFieldWithTable(0, sql.Integer, "t", "a", false)
+	fields := []plan.SortField{{Column: a, Order: plan.Descending}}
+	ten := expression.NewLiteral(int64(10), sql.BigInteger)
+	five := expression.NewLiteral(int64(5), sql.BigInteger)
+
+	require.Equal(
+		plan.NewTopN(fields, ten, table),
+		f.Apply(nil, plan.NewLimit(10, plan.NewSort(fields, table))),
+	)
+
+	notAnalyzed := plan.NewLimit(10, plan.NewOffset(5, plan.NewProject(
+		[]sql.Expression{a},
+		plan.NewSort(fields, table),
+	)))
+	expected := plan.NewLimit(10, plan.NewOffset(5, plan.NewProject(
+		[]sql.Expression{a},
+		plan.NewTopN(fields, expression.NewPlus(ten, five), table),
+	)))
+
+	analyzed := f.Apply(nil, notAnalyzed)
+	require.Equal(expected, analyzed)
+	require.Equal(expected, f.Apply(nil, analyzed))
+
+	// nodes that change the number of rows can't be between them
+	notAnalyzed = plan.NewLimit(10, plan.NewDistinct(plan.NewProject(
+		[]sql.Expression{a},
+		plan.NewSort(fields, table),
+	)))
+	require.Equal(notAnalyzed, f.Apply(nil, notAnalyzed))
+}
@@ -1,6 +1,10 @@
 package analyzer
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+
 	"gopkg.in/sqle/sqle.v0/sql"
 	"gopkg.in/sqle/sqle.v0/sql/expression"
 	"gopkg.in/sqle/sqle.v0/sql/plan"
@@ -8,15 +12,29 @@ import (
 
 var DefaultRules = []Rule{
 	{"resolve_tables", resolveTables},
+	{"resolve_having", resolveHaving},
+	{"resolve_on_duplicate_update", resolveOnDuplicateUpdate},
+	{"resolve_using_joins", resolveUsingJoins},
 	{"resolve_columns", resolveColumns},
 	{"resolve_database", resolveDatabase},
 	{"resolve_star", resolveStar},
 	{"resolve_functions", resolveFunctions},
+	{"fold_constants", foldConstants},
+	{"reorder_joins", reorderJoins},
+	{"hash_joins", hashJoins},
+	{"index_lookups", indexLookups},
+	{"pushdown", pushdown},
+	{"top_n", topN},
 }
 
 func resolveDatabase(a *Analyzer, n sql.Node) sql.Node {
-	_, ok := n.(*plan.ShowTables)
-	if !ok {
+	switch n.(type) {
+	case *plan.ShowTables, *plan.CreateTable, *plan.DropTable:
+	default:
+		return n
+	}
+
+	if n.Resolved() {
 		return n
 	}
 
@@ -25,7 +43,18 @@ func resolveDatabase(a *Analyzer, n sql.Node) sql.Node {
 		return n
 	}
 
-	return plan.NewShowTables(db)
+	switch n := n.(type) {
+	case *plan.CreateTable:
+		nc := *n
+		nc.Database = db
+		return &nc
+	case *plan.DropTable:
+		nc := *n
+		nc.Database = db
+		return &nc
+	default:
+		return plan.NewShowTables(db)
+	}
 }
 
 func resolveTables(a *Analyzer, n sql.Node) sql.Node {
@@ -35,7 +64,12 @@ func resolveTables(a *Analyzer, n sql.Node) sql.Node {
 			return n
 		}
 
-		rt, err := a.Catalog.Table(a.CurrentDatabase, t.Name)
+		db := t.Database
+		if db == "" {
+			db = a.CurrentDatabase
+		}
+
+		rt, err := a.Catalog.Table(db, t.Name)
 		if err != nil {
 			return n
 		}
@@ -50,27 +84,67 @@ func resolveStar(a *Analyzer, n sql.Node) sql.Node {
 			return n
 		}
 
-		p, ok := n.(*plan.Project)
-		if !ok {
+		switch p := n.(type) {
+		case *plan.Project:
+			if !p.Child.Resolved() {
+				return n
+			}
+
+			exprs, ok := expandStars(p.Expressions, p.Child.Schema())
+			if !ok {
+				return n
+			}
+
+			return plan.NewProject(exprs, p.Child)
+		case *plan.GroupBy:
+			if !p.Child.Resolved() {
+				return n
+			}
+
+			aggregate, ok := expandStars(p.Aggregate, p.Child.Schema())
+			if !ok {
+				return n
+			}
+
+			return plan.NewGroupBy(aggregate, p.Grouping, p.Child)
+		default:
 			return n
 		}
+	})
+}
 
-		if len(p.Expressions) != 1 {
-			return n
+// expandStars replaces the stars in the given list of expressions with the
+// columns of the schema they refer to. It returns false if there are no stars
+// or any of them does not refer to any column.
+func expandStars(exprs []sql.Expression, schema sql.Schema) ([]sql.Expression, bool) {
+	var expanded bool
+	var result []sql.Expression
+	for _, e := range exprs {
+		star, ok := e.(*expression.Star)
+		if !ok {
+			result = append(result, e)
+			continue
 		}
 
-		if _, ok := p.Expressions[0].(*expression.Star); !ok {
-			return n
+		var found bool
+		for i, col := range schema {
+			if star.Table != "" && star.Table != col.Source {
+				continue
+			}
+
+			found = true
+			result = append(result, expression.NewGetFieldWithTable(
+				i, col.Type, col.Source, col.Name, col.Nullable))
 		}
 
-		var exprs []sql.Expression
-		for i, e := range p.Child.Schema() {
-			gf := expression.NewGetField(i, e.Type, e.Name, e.Nullable)
-			exprs = append(exprs, gf)
+		if !found {
+			return nil, false
 		}
 
-		return plan.NewProject(exprs, p.Child)
-	})
+		expanded = true
+	}
+
+	return result, expanded
 }
 
 func resolveColumns(a *Analyzer, n sql.Node) sql.Node {
@@ -79,32 +153,35 @@ func resolveColumns(a *Analyzer, n sql.Node) sql.Node {
 			return n
 		}
 
-		if len(n.Children()) != 1 {
-			return n
-		}
-
-		child := n.Children()[0]
-		if !child.Resolved() {
+		children := n.Children()
+		if len(children) == 0 {
 			return n
 		}
 
-		colMap := map[string]*expression.GetField{}
-		for idx, child := range child.Schema() {
-			if _, ok := colMap[child.Name]; ok {
-				// There is no unambiguous resolution
+		// Rows seen by the expressions of a node with several children (such
+		// as joins) are the concatenation of the rows of its children.
+		var schema sql.Schema
+		for _, child := range children {
+			if !child.Resolved() {
 				return n
 			}
 
-			colMap[child.Name] = expression.NewGetField(idx, child.Type, child.Name, child.Nullable)
+			schema = append(schema, child.Schema()...)
 		}
 
+		colMap, ambiguous := indexColumns(schema)
 		return n.TransformExpressionsUp(func(e sql.Expression) sql.Expression {
 			uc, ok := e.(*expression.UnresolvedColumn)
 			if !ok {
 				return e
 			}
 
-			gf, ok := colMap[uc.Name()]
+			key := columnKey(uc)
+			if ambiguous[key] {
+				return e
+			}
+
+			gf, ok := colMap[key]
 			if !ok {
 				return e
 			}
@@ -114,6 +191,259 @@ func resolveColumns(a *Analyzer, n sql.Node) sql.Node {
 	})
 }
 
+// indexColumns indexes the columns of the schema both by their name and by
+// their name qualified with their source. The latter is only ambiguous if the
+// same table is used twice without aliases. Ambiguous keys have no unique
+// resolution and are reported apart.
+func indexColumns(
+	schema sql.Schema,
+) (map[string]*expression.GetField, map[string]bool) {
+	colMap := map[string]*expression.GetField{}
+	ambiguous := map[string]bool{}
+	for idx, col := range schema {
+		gf := expression.NewGetFieldWithTable(idx, col.Type, col.Source,
+			col.Name, col.Nullable)
+
+		keys := []string{col.Name}
+		if col.Source != "" {
+			keys = append(keys, col.Source+"."+col.Name)
+		}
+
+		for _, k := range keys {
+			if _, ok := colMap[k]; ok {
+				ambiguous[k] = true
+				continue
+			}
+
+			colMap[k] = gf
+		}
+	}
+
+	return colMap, ambiguous
+}
+
+// columnKey returns the key of the column in the index of indexColumns.
+func columnKey(uc *expression.UnresolvedColumn) string {
+	if uc.Table() != "" {
+		return uc.Table() + "." + uc.Name()
+	}
+
+	return uc.Name()
+}
+
+// resolveOnDuplicateUpdate resolves the columns of the ON DUPLICATE KEY UPDATE
+// clause of an insert, evaluated against the existing row of the table
+// followed by the inserted row. Columns refer to the existing row and
+// VALUES(col) to the inserted one.
+func resolveOnDuplicateUpdate(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		i, ok := n.(*plan.InsertInto)
+		if !ok || len(i.OnDuplicateUpdate) == 0 || !i.Left.Resolved() {
+			return n
+		}
+
+		var table string
+		if t, ok := i.Left.(sql.Nameable); ok {
+			table = t.Name()
+		}
+
+		schema := i.Left.Schema()
+		find := func(name string, offset int) sql.Expression {
+			for idx, col := range schema {
+				if col.Name == name {
+					return expression.NewGetFieldWithTable(offset+idx, col.Type,
+						col.Source, col.Name, col.Nullable)
+				}
+			}
+
+			return nil
+		}
+
+		resolve := func(e sql.Expression) sql.Expression {
+			switch c := e.(type) {
+			case *expression.UnresolvedColumn:
+				if c.Table() != "" && c.Table() != table {
+					return e
+				}
+
+				if gf := find(c.Name(), 0); gf != nil {
+					return gf
+				}
+			case *expression.UnresolvedValuesColumn:
+				if gf := find(c.Name(), len(schema)); gf != nil {
+					return gf
+				}
+			}
+
+			return e
+		}
+
+		fields := make([]plan.UpdateField, len(i.OnDuplicateUpdate))
+		for idx, f := range i.OnDuplicateUpdate {
+			fields[idx] = plan.UpdateField{
+				Column: f.Column.TransformUp(resolve),
+				Value:  f.Value.TransformUp(resolve),
+			}
+		}
+
+		nc := *i
+		nc.OnDuplicateUpdate = fields
+		return &nc
+	})
+}
+
+// resolveUsingJoins turns the USING columns of a join into its condition once
+// both sides are resolved. As in MySQL, each USING column appears only once
+// in the result, followed by the rest of the columns of the left side and
+// then of the right side, so the join is wrapped in a Project. USING columns
+// are taken from the left side, except in right joins, where they are taken
+// from the right side and its columns go first, as it's the side all rows
+// come from.
+func resolveUsingJoins(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		left, right, using, ok := usingJoin(n)
+		if !ok {
+			return n
+		}
+
+		cond, ok := usingCondition(left, right, using)
+		if !ok {
+			return n
+		}
+
+		var join sql.Node
+		switch n.(type) {
+		case *plan.InnerJoin:
+			join = plan.NewInnerJoin(left, right, cond)
+		case *plan.LeftJoin:
+			join = plan.NewLeftJoin(left, right, cond)
+		case *plan.RightJoin:
+			join = plan.NewRightJoin(left, right, cond)
+		}
+
+		_, fromRight := n.(*plan.RightJoin)
+		return plan.NewProject(
+			usingProjection(join.Schema(), len(left.Schema()), using, fromRight),
+			join,
+		)
+	})
+}
+
+// usingJoin returns the sides and the USING columns of a join with them.
+func usingJoin(n sql.Node) (left, right sql.Node, using []string, ok bool) {
+	switch j := n.(type) {
+	case *plan.InnerJoin:
+		left, right, using = j.Left, j.Right, j.UsingColumns
+	case *plan.LeftJoin:
+		left, right, using = j.Left, j.Right, j.UsingColumns
+	case *plan.RightJoin:
+		left, right, using = j.Left, j.Right, j.UsingColumns
+	}
+
+	return left, right, using, len(using) > 0
+}
+
+// usingCondition builds the condition of a join with the given USING
+// columns, which must exist exactly once on each side. It returns false if
+// the condition can't be built yet.
+func usingCondition(left, right sql.Node, cols []string) (sql.Expression, bool) {
+	if !left.Resolved() || !right.Resolved() {
+		return nil, false
+	}
+
+	ls := left.Schema()
+	rs := right.Schema()
+
+	var cond sql.Expression
+	for _, col := range cols {
+		li := indexOfUniqueColumn(ls, col)
+		ri := indexOfUniqueColumn(rs, col)
+		if li < 0 || ri < 0 {
+			return nil, false
+		}
+
+		eq := expression.NewEquals(
+			expression.NewGetFieldWithTable(li, ls[li].Type, ls[li].Source,
+				ls[li].Name, ls[li].Nullable),
+			expression.NewGetFieldWithTable(len(ls)+ri, rs[ri].Type, rs[ri].Source,
+				rs[ri].Name, rs[ri].Nullable),
+		)
+
+		if cond == nil {
+			cond = eq
+		} else {
+			cond = expression.NewAnd(cond, eq)
+		}
+	}
+
+	return cond, true
+}
+
+// usingProjection returns the fields of the rows of a join with the given
+// schema and USING columns, with each USING column only once. The first
+// leftWidth columns of the schema come from its left side.
+func usingProjection(
+	schema sql.Schema,
+	leftWidth int,
+	cols []string,
+	fromRight bool,
+) []sql.Expression {
+	isUsing := make(map[string]bool, len(cols))
+	for _, col := range cols {
+		isUsing[col] = true
+	}
+
+	first, second := 0, leftWidth
+	firstWidth, secondWidth := leftWidth, len(schema)-leftWidth
+	if fromRight {
+		first, second = second, first
+		firstWidth, secondWidth = secondWidth, firstWidth
+	}
+
+	field := func(idx int) sql.Expression {
+		c := schema[idx]
+		return expression.NewGetFieldWithTable(idx, c.Type, c.Source, c.Name, c.Nullable)
+	}
+
+	var fields []sql.Expression
+	for i := first; i < first+firstWidth; i++ {
+		if isUsing[schema[i].Name] {
+			fields = append(fields, field(i))
+		}
+	}
+
+	for i := first; i < first+firstWidth; i++ {
+		if !isUsing[schema[i].Name] {
+			fields = append(fields, field(i))
+		}
+	}
+
+	for i := second; i < second+secondWidth; i++ {
+		if !isUsing[schema[i].Name] {
+			fields = append(fields, field(i))
+		}
+	}
+
+	return fields
+}
+
+func indexOfUniqueColumn(s sql.Schema, name string) int {
+	idx := -1
+	for i, c := range s {
+		if c.Name != name {
+			continue
+		}
+
+		if idx >= 0 {
+			return -1
+		}
+
+		idx = i
+	}
+
+	return idx
+}
+
 func resolveFunctions(a *Analyzer, n sql.Node) sql.Node {
 	return n.TransformUp(func(n sql.Node) sql.Node {
 		if n.Resolved() {
@@ -137,7 +467,135 @@ func resolveFunctions(a *Analyzer, n sql.Node) sql.Node {
 				return e
 			}
 
+			if uf.Distinct {
+				// COUNT is the only function with a distinct mode.
+				c, ok := rf.(*expression.Count)
+				if !ok {
+					return e
+				}
+
+				return expression.NewCountDistinct(c.Child)
+			}
+
 			return rf
 		})
 	})
 }
+
+// resolveHaving moves the aggregations in the condition of a Having to the
+// GroupBy below it, as it is the one computing them. Aggregations not in the
+// GroupBy are added to it and projected away after the Having. This is done
+// before the columns are resolved, as the arguments of the aggregations refer
+// to the columns of the child of the GroupBy and not to the GroupBy itself.
+// Once the GroupBy is resolved, the moved aggregations are replaced with
+// fields of its rows.
+func resolveHaving(a *Analyzer, n sql.Node) sql.Node {
+	return n.TransformUp(func(n sql.Node) sql.Node {
+		h, ok := n.(*plan.Having)
+		if !ok || h.Resolved() {
+			return n
+		}
+
+		g, ok := h.Child.(*plan.GroupBy)
+		if !ok {
+			return n
+		}
+
+		if !g.Resolved() {
+			return moveHavingAggregations(h, g)
+		}
+
+		return resolveHavingAggregations(h, g)
+	})
+}
+
+func moveHavingAggregations(h *plan.Having, g *plan.GroupBy) sql.Node {
+	aggregate := g.Aggregate
+	visible := len(aggregate)
+	var moved bool
+	cond := h.Cond.TransformUp(func(e sql.Expression) sql.Expression {
+		if uf, ok := e.(*expression.UnresolvedFunction); !ok || !uf.IsAggregate {
+			return e
+		}
+
+		moved = true
+		for i, agg := range aggregate {
+			if alias, ok := agg.(*expression.Alias); ok {
+				agg = alias.Child
+			}
+
+			if reflect.DeepEqual(agg, e) {
+				return &aggregationRef{i, visible}
+			}
+		}
+
+		aggregate = append(aggregate, e)
+		return &aggregationRef{len(aggregate) - 1, visible}
+	})
+
+	if !moved {
+		return h
+	}
+
+	return plan.NewHaving(cond, plan.NewGroupBy(aggregate, g.Grouping, g.Child))
+}
+
+func resolveHavingAggregations(h *plan.Having, g *plan.GroupBy) sql.Node {
+	schema := g.Schema()
+	visible := len(schema)
+	cond := h.Cond.TransformUp(func(e sql.Expression) sql.Expression {
+		ref, ok := e.(*aggregationRef)
+		if !ok {
+			return e
+		}
+
+		visible = ref.visible
+		c := schema[ref.index]
+		return expression.NewGetField(ref.index, c.Type, c.Name, c.Nullable)
+	})
+
+	var n sql.Node = plan.NewHaving(cond, g)
+	if visible == len(schema) {
+		return n
+	}
+
+	exprs := make([]sql.Expression, visible)
+	for i, c := range schema[:visible] {
+		exprs[i] = expression.NewGetField(i, c.Type, c.Name, c.Nullable)
+	}
+
+	return plan.NewProject(exprs, n)
+}
+
+// aggregationRef is a reference to an aggregation of the GroupBy below a
+// Having, where visible is the number of aggregations of the GroupBy that are
+// not only used by the Having.
+type aggregationRef struct {
+	index   int
+	visible int
+}
+
+func (*aggregationRef) Resolved() bool {
+	return false
+}
+
+func (*aggregationRef) Type() sql.Type {
+	return sql.Null
+}
+
+func (r *aggregationRef) Name() string {
+	return fmt.Sprintf("aggregation(%d)", r.index)
+}
+
+func (*aggregationRef) IsNullable() bool {
+	return true
+}
+
+func (*aggregationRef) Eval(sql.Row) (interface{}, error) {
+	return nil, errors.New("unresolved aggregation")
+}
+
+func (r *aggregationRef) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	n := *r
+	return f(&n)
+}
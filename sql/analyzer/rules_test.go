@@ -10,6 +10,7 @@ import (
 	"gopkg.in/sqle/sqle.v0/sql/plan"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_resolveTables(t *testing.T) {
@@ -40,6 +41,79 @@ func Test_resolveTables(t *testing.T) {
 
 }
 
+func Test_resolveDatabase(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("resolve_database")
+
+	db := mem.NewDatabase("mydb")
+	catalog := &sql.Catalog{Databases: []sql.Database{db}}
+
+	a := analyzer.New(catalog)
+	a.CurrentDatabase = "mydb"
+
+	schema := sql.Schema{{Name: "i", Type: sql.Integer}}
+	analyzed := f.Apply(a, plan.NewCreateTable(&sql.UnresolvedDatabase{}, "t", schema, true))
+	require.Equal(plan.NewCreateTable(db, "t", schema, true), analyzed)
+
+	analyzed = f.Apply(a, plan.NewDropTable(&sql.UnresolvedDatabase{}, "t", false))
+	require.Equal(plan.NewDropTable(db, "t", false), analyzed)
+
+	analyzed = f.Apply(a, plan.NewShowTables(&sql.UnresolvedDatabase{}))
+	require.Equal(plan.NewShowTables(db), analyzed)
+
+	a.CurrentDatabase = "otherdb"
+	notAnalyzed := plan.NewDropTable(&sql.UnresolvedDatabase{}, "t", false)
+	require.Equal(notAnalyzed, f.Apply(a, notAnalyzed))
+}
+
+func Test_resolveOnDuplicateUpdate(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("resolve_on_duplicate_update")
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+		{Name: "b", Type: sql.String},
+	})
+	values := plan.NewValues([][]sql.Expression{{
+		expression.NewLiteral(int64(1), sql.BigInteger),
+	}})
+
+	notAnalyzed := plan.NewInsertOnDuplicateKeyUpdate(table, values, []string{"a"},
+		[]plan.UpdateField{{
+			Column: expression.NewUnresolvedColumn("b"),
+			Value: expression.NewPlus(
+				expression.NewUnresolvedQualifiedColumn("t", "a"),
+				expression.NewUnresolvedValuesColumn("a"),
+			),
+		}},
+	)
+
+	expected := plan.NewInsertOnDuplicateKeyUpdate(table, values, []string{"a"},
+		[]plan.UpdateField{{
+			Column: expression.NewGetFieldWithTable(1, sql.String, "t", "b", false),
+			Value: expression.NewPlus(
+				expression.NewGetFieldWithTable(0, sql.BigInteger, "t", "a", false),
+				expression.NewGetFieldWithTable(2, sql.BigInteger, "t", "a", false),
+			),
+		}},
+	)
+
+	analyzed := f.Apply(nil, notAnalyzed)
+	require.Equal(expected, analyzed)
+	require.True(analyzed.Resolved())
+
+	// columns of other tables are not resolved
+	notAnalyzed = plan.NewInsertOnDuplicateKeyUpdate(table, values, []string{"a"},
+		[]plan.UpdateField{{
+			Column: expression.NewUnresolvedQualifiedColumn("other", "b"),
+			Value:  expression.NewLiteral("x", sql.String),
+		}},
+	)
+	require.False(f.Apply(nil, notAnalyzed).Resolved())
+}
+
 func Test_resolveTables_Nested(t *testing.T) {
 	assert := assert.New(t)
 
@@ -67,6 +141,58 @@ func Test_resolveTables_Nested(t *testing.T) {
 	assert.Equal(expected, analyzed)
 }
 
+func Test_resolveUsingJoins(t *testing.T) {
+	assert := assert.New(t)
+
+	f := getRule("resolve_using_joins")
+
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "b", Type: sql.String},
+		{Name: "c", Type: sql.Integer},
+	})
+
+	analyzed := f.Apply(nil, plan.NewInnerJoinUsing(left, right, []string{"b"}))
+	expected := plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetFieldWithTable(1, sql.String, "left", "b", false),
+			expression.NewGetFieldWithTable(0, sql.Integer, "left", "a", false),
+			expression.NewGetFieldWithTable(3, sql.Integer, "right", "c", false),
+		},
+		plan.NewInnerJoin(left, right,
+			expression.NewEquals(
+				expression.NewGetFieldWithTable(1, sql.String, "left", "b", false),
+				expression.NewGetFieldWithTable(2, sql.String, "right", "b", false),
+			),
+		),
+	)
+	assert.Equal(expected, analyzed)
+
+	// in right joins, the USING columns come from the right side
+	analyzed = f.Apply(nil, plan.NewRightJoinUsing(left, right, []string{"b"}))
+	expected = plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetFieldWithTable(2, sql.String, "right", "b", false),
+			expression.NewGetFieldWithTable(3, sql.Integer, "right", "c", false),
+			expression.NewGetFieldWithTable(0, sql.Integer, "left", "a", true),
+		},
+		plan.NewRightJoin(left, right,
+			expression.NewEquals(
+				expression.NewGetFieldWithTable(1, sql.String, "left", "b", false),
+				expression.NewGetFieldWithTable(2, sql.String, "right", "b", false),
+			),
+		),
+	)
+	assert.Equal(expected, analyzed)
+
+	notAnalyzed := plan.NewInnerJoinUsing(left, right, []string{"a"})
+	analyzed = f.Apply(nil, notAnalyzed)
+	assert.Equal(notAnalyzed, analyzed)
+}
+
 func getRule(name string) analyzer.Rule {
 	for _, rule := range analyzer.DefaultRules {
 		if rule.Name == name {
@@ -75,3 +201,140 @@ func getRule(name string) analyzer.Rule {
 	}
 	panic("missing rule")
 }
+
+func Test_resolveColumns_Qualified(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("resolve_columns")
+
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "id", Type: sql.Integer},
+		{Name: "a", Type: sql.String},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "id", Type: sql.Integer},
+	})
+
+	notAnalyzed := plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedQualifiedColumn("left", "id"),
+			expression.NewUnresolvedQualifiedColumn("r", "id"),
+			expression.NewUnresolvedColumn("a"),
+		},
+		plan.NewCrossJoin(left, plan.NewTableAlias("r", right)),
+	)
+
+	expected := plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetFieldWithTable(0, sql.Integer, "left", "id", false),
+			expression.NewGetFieldWithTable(2, sql.Integer, "r", "id", false),
+			expression.NewGetFieldWithTable(1, sql.String, "left", "a", false),
+		},
+		plan.NewCrossJoin(left, plan.NewTableAlias("r", right)),
+	)
+
+	require.Equal(expected, f.Apply(nil, notAnalyzed))
+
+	// unqualified columns present on both sides are ambiguous, as well as
+	// qualified columns whose table is used twice without aliases
+	join := plan.NewCrossJoin(left, plan.NewCrossJoin(left, right))
+	for _, col := range []sql.Expression{
+		expression.NewUnresolvedColumn("id"),
+		expression.NewUnresolvedQualifiedColumn("left", "id"),
+	} {
+		notAnalyzed = plan.NewProject([]sql.Expression{col}, join)
+		require.Equal(notAnalyzed, f.Apply(nil, notAnalyzed))
+	}
+
+	notAnalyzed = plan.NewProject(
+		[]sql.Expression{expression.NewUnresolvedQualifiedColumn("right", "id")},
+		join,
+	)
+	require.Equal(plan.NewProject(
+		[]sql.Expression{
+			expression.NewGetFieldWithTable(4, sql.Integer, "right", "id", false),
+		},
+		join,
+	), f.Apply(nil, notAnalyzed))
+}
+
+func Test_resolveStar(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("resolve_star")
+
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "c", Type: sql.Integer},
+	})
+	join := plan.NewCrossJoin(left, plan.NewTableAlias("r", right))
+
+	a := expression.NewGetFieldWithTable(0, sql.Integer, "left", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.String, "left", "b", false)
+	c := expression.NewGetFieldWithTable(2, sql.Integer, "r", "c", false)
+
+	analyzed := f.Apply(nil, plan.NewProject(
+		[]sql.Expression{expression.NewStar()},
+		join,
+	))
+	require.Equal(plan.NewProject([]sql.Expression{a, b, c}, join), analyzed)
+
+	analyzed = f.Apply(nil, plan.NewProject(
+		[]sql.Expression{c, expression.NewQualifiedStar("left")},
+		join,
+	))
+	require.Equal(plan.NewProject([]sql.Expression{c, a, b}, join), analyzed)
+
+	count := expression.NewCount(expression.NewStar())
+	analyzed = f.Apply(nil, plan.NewGroupBy(
+		[]sql.Expression{expression.NewQualifiedStar("r"), count},
+		[]sql.Expression{c},
+		join,
+	))
+	require.Equal(plan.NewGroupBy(
+		[]sql.Expression{c, count},
+		[]sql.Expression{c},
+		join,
+	), analyzed)
+
+	// stars of unknown tables can't be expanded
+	notAnalyzed := plan.NewProject(
+		[]sql.Expression{expression.NewQualifiedStar("right")},
+		join,
+	)
+	require.Equal(notAnalyzed, f.Apply(nil, notAnalyzed))
+}
+
+func Test_resolveFunctions_Distinct(t *testing.T) {
+	require := require.New(t)
+
+	f := getRule("resolve_functions")
+
+	table := mem.NewTable("mytable", sql.Schema{{Name: "i", Type: sql.Integer}})
+	catalog := sql.NewCatalog()
+	require.NoError(expression.RegisterDefaults(catalog))
+	a := analyzer.New(catalog)
+
+	i := expression.NewGetFieldWithTable(0, sql.Integer, "mytable", "i", false)
+	analyzed := f.Apply(a, plan.NewGroupBy(
+		[]sql.Expression{expression.NewUnresolvedDistinctFunction("count", i)},
+		nil,
+		table,
+	))
+	require.Equal(plan.NewGroupBy(
+		[]sql.Expression{expression.NewCountDistinct(i)},
+		nil,
+		table,
+	), analyzed)
+
+	// only COUNT supports DISTINCT
+	notAnalyzed := plan.NewGroupBy(
+		[]sql.Expression{expression.NewUnresolvedDistinctFunction("first", i)},
+		nil,
+		table,
+	)
+	require.Equal(notAnalyzed, f.Apply(a, notAnalyzed))
+}
@@ -2,12 +2,16 @@ package analyzer
 
 import (
 	"errors"
+	"fmt"
 
 	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
 	"gopkg.in/sqle/sqle.v0/sql/plan"
 )
 
 var DefaultValidationRules = []ValidationRule{
+	{"validate_using_joins", validateUsingJoins},
+	{"validate_ambiguous_columns", validateAmbiguousColumns},
 	{"validate_resolved", validateIsResolved},
 	{"validate_order_by", validateOrderBy},
 }
@@ -20,6 +24,79 @@ func validateIsResolved(a *Analyzer, n sql.Node) error {
 	return nil
 }
 
+// validateUsingJoins checks the USING columns of the joins whose sides are
+// resolved exist exactly once on each side.
+func validateUsingJoins(a *Analyzer, n sql.Node) error {
+	left, right, using, ok := usingJoin(n)
+	if !ok || !left.Resolved() || !right.Resolved() {
+		return nil
+	}
+
+	for _, col := range using {
+		for _, s := range []sql.Schema{left.Schema(), right.Schema()} {
+			switch countColumns(s, col) {
+			case 0:
+				return fmt.Errorf("column %s not found in both tables", col)
+			case 1:
+			default:
+				return fmt.Errorf("ambiguous column name %s", col)
+			}
+		}
+	}
+
+	return nil
+}
+
+func countColumns(s sql.Schema, name string) int {
+	var n int
+	for _, c := range s {
+		if c.Name == name {
+			n++
+		}
+	}
+
+	return n
+}
+
+// validateAmbiguousColumns checks the columns left unresolved in a node whose
+// children are resolved are not ambiguous. As the expressions of its children
+// are resolved, all the unresolved columns found belong to the node. The
+// columns of an insert are resolved against its table instead, so it's not
+// checked.
+func validateAmbiguousColumns(a *Analyzer, n sql.Node) error {
+	if _, ok := n.(*plan.InsertInto); ok || n.Resolved() {
+		return nil
+	}
+
+	children := n.Children()
+	if len(children) == 0 {
+		return nil
+	}
+
+	var schema sql.Schema
+	for _, child := range children {
+		if !child.Resolved() {
+			return nil
+		}
+
+		schema = append(schema, child.Schema()...)
+	}
+
+	_, ambiguous := indexColumns(schema)
+
+	var err error
+	n.TransformExpressionsUp(func(e sql.Expression) sql.Expression {
+		uc, ok := e.(*expression.UnresolvedColumn)
+		if ok && err == nil && ambiguous[columnKey(uc)] {
+			err = fmt.Errorf("ambiguous column name %s", columnKey(uc))
+		}
+
+		return e
+	})
+
+	return err
+}
+
 func validateOrderBy(a *Analyzer, n sql.Node) error {
 	switch n := n.(type) {
 	case *plan.Sort:
@@ -3,6 +3,7 @@ package analyzer_test
 import (
 	"testing"
 
+	"gopkg.in/sqle/sqle.v0/mem"
 	"gopkg.in/sqle/sqle.v0/sql"
 	"gopkg.in/sqle/sqle.v0/sql/analyzer"
 	"gopkg.in/sqle/sqle.v0/sql/expression"
@@ -45,6 +46,57 @@ func Test_orderBy(t *testing.T) {
 	assert.Error(err)
 }
 
+func Test_usingJoins(t *testing.T) {
+	require := require.New(t)
+
+	vr := getValidationRule("validate_using_joins")
+
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "b", Type: sql.String},
+		{Name: "c", Type: sql.Integer},
+	})
+
+	require.NoError(vr.Apply(nil, plan.NewInnerJoinUsing(left, right, []string{"b"})))
+	require.NoError(vr.Apply(nil, plan.NewInnerJoinUsing(
+		plan.NewUnresolvedTable("foo"), right, []string{"a"})))
+
+	err := vr.Apply(nil, plan.NewLeftJoinUsing(left, right, []string{"b", "a"}))
+	require.EqualError(err, "column a not found in both tables")
+
+	err = vr.Apply(nil, plan.NewInnerJoinUsing(
+		plan.NewCrossJoin(left, right), right, []string{"b"}))
+	require.EqualError(err, "ambiguous column name b")
+}
+
+func Test_ambiguousColumns(t *testing.T) {
+	require := require.New(t)
+
+	vr := getValidationRule("validate_ambiguous_columns")
+
+	left := mem.NewTable("left", sql.Schema{{Name: "b", Type: sql.String}})
+	right := mem.NewTable("right", sql.Schema{{Name: "b", Type: sql.String}})
+
+	project := func(col *expression.UnresolvedColumn) sql.Node {
+		return plan.NewProject(
+			[]sql.Expression{col},
+			plan.NewCrossJoin(left, right),
+		)
+	}
+
+	err := vr.Apply(nil, project(expression.NewUnresolvedColumn("b")))
+	require.EqualError(err, "ambiguous column name b")
+
+	err = vr.Apply(nil, project(expression.NewUnresolvedQualifiedColumn("left", "b")))
+	require.NoError(err)
+
+	err = vr.Apply(nil, project(expression.NewUnresolvedColumn("c")))
+	require.NoError(err)
+}
+
 type dummyNode struct{ resolved bool }
 
 func (n dummyNode) Resolved() bool                             { return n.resolved }
@@ -2,6 +2,7 @@ package sql
 
 import (
 	"errors"
+	"fmt"
 )
 
 type Nameable interface {
@@ -22,7 +23,7 @@ type Expression interface {
 	Type() Type
 	Name() string
 	IsNullable() bool
-	Eval(Row) interface{}
+	Eval(Row) (interface{}, error)
 	TransformUp(func(Expression) Expression) Expression
 }
 
@@ -37,7 +38,7 @@ type AggregationExpression interface {
 	// NewBuffer creates a new aggregation buffer and returns it as a Row.
 	NewBuffer() Row
 	// Update updates the given buffer with the given row.
-	Update(buffer, row Row)
+	Update(buffer, row Row) error
 	// Merge merges a partial buffer into a global one.
 	Merge(buffer, partial Row)
 }
@@ -61,13 +62,176 @@ type Table interface {
 	Node
 }
 
+// FilteredTable is a table that can filter its rows itself, so they don't
+// need to be read and filtered afterwards.
+type FilteredTable interface {
+	Table
+	// HandledFilters returns the filters among the given ones the table can
+	// handle. The fields of the filters refer to the schema of the table.
+	HandledFilters(filters []Expression) []Expression
+	// WithFilters returns an iterator over the rows of the table matching
+	// all the given filters, which are among the handled ones. If columns is
+	// not nil, only the values of the columns with those names are needed,
+	// as in ProjectedTable.
+	WithFilters(columns []string, filters []Expression) (RowIter, error)
+}
+
+// ProjectedTable is a table that can read only some of its columns.
+type ProjectedTable interface {
+	Table
+	// WithProject returns an iterator over the rows of the table, where only
+	// the values of the columns with the given names are needed. The rows
+	// still have a value for every column of the schema, but the rest of
+	// them may be nil.
+	WithProject(columns []string) (RowIter, error)
+}
+
+// RowCountEstimator is implemented by nodes that can estimate the number of
+// rows they return. It is used by the analyzer to optimize query plans.
+type RowCountEstimator interface {
+	EstimatedRowCount() int64
+}
+
 type Inserter interface {
 	Insert(row Row) error
 }
 
+// KeyInserter is an Inserter that generates a key, such as an auto increment
+// value, for every inserted row.
+type KeyInserter interface {
+	Inserter
+	// InsertWithKey inserts the row and returns the key generated for it.
+	InsertWithKey(row Row) (int64, error)
+}
+
+// Updater is a table whose rows can be updated.
+type Updater interface {
+	// Update replaces each of the rows old of the table with the row in the
+	// same position of new, all at once. Equal rows in old replace different
+	// rows of the table.
+	Update(old, new []Row) error
+}
+
+// Deleter is a table whose rows can be deleted.
+type Deleter interface {
+	// Delete removes the given rows from the table, all at once. Equal rows
+	// remove different rows of the table.
+	Delete(rows []Row) error
+}
+
+// ColumnAlterer is a table whose columns can be added, dropped and renamed.
+type ColumnAlterer interface {
+	// AddColumn appends a column to the table, whose existing rows get the
+	// default value of the column.
+	AddColumn(column *Column) error
+	// DropColumn removes the column with the given name.
+	DropColumn(name string) error
+	// RenameColumn changes the name of a column.
+	RenameColumn(name, newName string) error
+}
+
+// IndexType is the data structure of an index, which determines the lookups
+// it supports.
+type IndexType string
+
+const (
+	// HashIndex is an index that only supports equality lookups.
+	HashIndex IndexType = "hash"
+	// BTreeIndex is an ordered index that supports equality and range
+	// lookups.
+	BTreeIndex IndexType = "btree"
+)
+
+// Index describes an index on a column of a table.
+type Index struct {
+	// Name is the name of the index, unique in its table.
+	Name string
+	// Column is the name of the indexed column.
+	Column string
+	// Type is the data structure of the index.
+	Type IndexType
+}
+
+// IndexRange is a range of values of an indexed column. Rows with a NULL
+// value in the column are never in a range.
+type IndexRange struct {
+	// Lower and Upper are the bounds of the range, or nil if the range is
+	// unbounded on that side.
+	Lower, Upper interface{}
+	// LowerInclusive and UpperInclusive are true if the values equal to
+	// the bounds are in the range.
+	LowerInclusive, UpperInclusive bool
+}
+
+// NewIndexPoint returns the range containing only the given value.
+func NewIndexPoint(value interface{}) IndexRange {
+	return IndexRange{value, value, true, true}
+}
+
+// IndexableTable is a table whose rows can be looked up using indexes.
+type IndexableTable interface {
+	Table
+	// CreateIndex creates an index and adds the rows of the table to it.
+	CreateIndex(index Index) error
+	// Indexes returns the indexes of the table.
+	Indexes() []Index
+	// IndexLookup returns the rows of the table whose value for the column
+	// of the given index is in the range. Hash indexes only support ranges
+	// with a single value.
+	IndexLookup(index string, r IndexRange) (RowIter, error)
+}
+
+// Executor is a node that modifies data, such as an INSERT, and reports the
+// outcome of the modification.
+type Executor interface {
+	Node
+	Execute() (Result, error)
+}
+
+// Result is the outcome of executing an Executor.
+type Result struct {
+	// RowsAffected is the number of rows inserted, updated or deleted. As in
+	// MySQL, a row replaced or updated because of a duplicate key counts
+	// as two rows.
+	RowsAffected int64
+	// LastInsertID is the last key generated for an inserted row, or 0 if
+	// the table does not generate keys.
+	LastInsertID int64
+	// Inserted, Updated and Skipped are the number of rows of an INSERT
+	// that were inserted, that updated an existing row with a duplicate key
+	// and that were skipped because of a duplicate key.
+	Inserted, Updated, Skipped int64
+}
+
+// DuplicateKeyError is returned by tables when a row has the same values for
+// a unique key as a row already in the table.
+type DuplicateKeyError struct {
+	// Key is the name of the key.
+	Key string
+	// Existing is the row already in the table.
+	Existing Row
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate entry for key %s", e.Key)
+}
+
 type Database interface {
 	Nameable
 	Tables() map[string]Table
 }
 
+// Alterable is a database whose tables can be created and dropped.
+type Alterable interface {
+	Database
+	// Create creates a new table with the given name and schema.
+	Create(name string, schema Schema) error
+	// Drop removes the table with the given name.
+	Drop(name string) error
+}
+
 var ErrInvalidType = errors.New("invalid type")
+
+// ErrNotNullable is returned when NULL is stored in a column that is not
+// nullable.
+var ErrNotNullable = errors.New("NULL value not allowed")
@@ -8,13 +8,27 @@ import (
 
 type Count struct {
 	UnaryExpression
+	// Distinct is true if only distinct values are counted.
+	Distinct bool
 }
 
 func NewCount(e sql.Expression) *Count {
-	return &Count{UnaryExpression{e}}
+	return &Count{UnaryExpression{e}, false}
 }
 
+// NewCountDistinct creates a new Count expression that only counts distinct
+// non-NULL values.
+func NewCountDistinct(e sql.Expression) *Count {
+	return &Count{UnaryExpression{e}, true}
+}
+
+// NewBuffer creates a buffer with the current count or, if Distinct is
+// true, with the set of values seen so far.
 func (c *Count) NewBuffer() sql.Row {
+	if c.Distinct {
+		return sql.NewRow(map[string]struct{}{})
+	}
+
 	return sql.NewRow(int32(0))
 }
 
@@ -35,36 +49,61 @@ func (c *Count) Resolved() bool {
 }
 
 func (c *Count) Name() string {
+	if c.Distinct {
+		return fmt.Sprintf("count(distinct %s)", c.Child.Name())
+	}
+
 	return fmt.Sprintf("count(%s)", c.Child.Name())
 }
 
 func (c *Count) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
 	nc := c.UnaryExpression.Child.TransformUp(f)
-	return f(NewCount(nc))
+	return f(&Count{UnaryExpression{nc}, c.Distinct})
 }
 
-func (c *Count) Update(buffer, row sql.Row) {
-	var inc bool
+func (c *Count) Update(buffer, row sql.Row) error {
 	if _, ok := c.Child.(*Star); ok {
-		inc = true
-	} else {
-		v := c.Child.Eval(row)
-		if v != nil {
-			inc = true
-		}
+		buffer[0] = buffer[0].(int32) + int32(1)
+		return nil
+	}
+
+	v, err := c.Child.Eval(row)
+	if err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
 	}
 
-	if inc {
+	if c.Distinct {
+		buffer[0].(map[string]struct{})[fmt.Sprintf("%#v", v)] = struct{}{}
+	} else {
 		buffer[0] = buffer[0].(int32) + int32(1)
 	}
+
+	return nil
 }
 
 func (c *Count) Merge(buffer, partial sql.Row) {
+	if c.Distinct {
+		seen := buffer[0].(map[string]struct{})
+		for k := range partial[0].(map[string]struct{}) {
+			seen[k] = struct{}{}
+		}
+
+		return
+	}
+
 	buffer[0] = buffer[0].(int32) + partial[0].(int32)
 }
 
-func (c *Count) Eval(buffer sql.Row) interface{} {
-	return buffer[0]
+func (c *Count) Eval(buffer sql.Row) (interface{}, error) {
+	if c.Distinct {
+		return int32(len(buffer[0].(map[string]struct{}))), nil
+	}
+
+	return buffer[0], nil
 }
 
 type First struct {
@@ -92,10 +131,17 @@ func (e *First) TransformUp(f func(sql.Expression) sql.Expression) sql.Expressio
 	return f(NewFirst(nc))
 }
 
-func (e *First) Update(buffer, row sql.Row) {
+func (e *First) Update(buffer, row sql.Row) error {
 	if buffer[0] == nil {
-		buffer[0] = e.Child.Eval(row)
+		v, err := e.Child.Eval(row)
+		if err != nil {
+			return err
+		}
+
+		buffer[0] = v
 	}
+
+	return nil
 }
 
 func (e *First) Merge(buffer, partial sql.Row) {
@@ -104,6 +150,6 @@ func (e *First) Merge(buffer, partial sql.Row) {
 	}
 }
 
-func (e *First) Eval(buffer sql.Row) interface{} {
-	return buffer[0]
+func (e *First) Eval(buffer sql.Row) (interface{}, error) {
+	return buffer[0], nil
 }
@@ -20,20 +20,20 @@ func TestCount_Eval_1(t *testing.T) {
 
 	c := NewCount(NewLiteral(1, sql.Integer))
 	b := c.NewBuffer()
-	assert.Equal(int32(0), c.Eval(b))
+	assert.Equal(int32(0), eval(t, c, b))
 
 	c.Update(b, nil)
 	c.Update(b, sql.NewRow("foo"))
 	c.Update(b, sql.NewRow(1))
 	c.Update(b, sql.NewRow(nil))
 	c.Update(b, sql.NewRow(1, 2, 3))
-	assert.Equal(int32(5), c.Eval(b))
+	assert.Equal(int32(5), eval(t, c, b))
 
 	b2 := c.NewBuffer()
 	c.Update(b2, nil)
 	c.Update(b2, sql.NewRow("foo"))
 	c.Merge(b, b2)
-	assert.Equal(int32(7), c.Eval(b))
+	assert.Equal(int32(7), eval(t, c, b))
 }
 
 func TestCount_Eval_Star(t *testing.T) {
@@ -41,20 +41,20 @@ func TestCount_Eval_Star(t *testing.T) {
 
 	c := NewCount(NewStar())
 	b := c.NewBuffer()
-	assert.Equal(int32(0), c.Eval(b))
+	assert.Equal(int32(0), eval(t, c, b))
 
 	c.Update(b, nil)
 	c.Update(b, sql.NewRow("foo"))
 	c.Update(b, sql.NewRow(1))
 	c.Update(b, sql.NewRow(nil))
 	c.Update(b, sql.NewRow(1, 2, 3))
-	assert.Equal(int32(5), c.Eval(b))
+	assert.Equal(int32(5), eval(t, c, b))
 
 	b2 := c.NewBuffer()
 	c.Update(b2, sql.NewRow())
 	c.Update(b2, sql.NewRow("foo"))
 	c.Merge(b, b2)
-	assert.Equal(int32(7), c.Eval(b))
+	assert.Equal(int32(7), eval(t, c, b))
 }
 
 func TestCount_Eval_String(t *testing.T) {
@@ -62,13 +62,38 @@ func TestCount_Eval_String(t *testing.T) {
 
 	c := NewCount(NewGetField(0, sql.String, "", true))
 	b := c.NewBuffer()
-	assert.Equal(int32(0), c.Eval(b))
+	assert.Equal(int32(0), eval(t, c, b))
 
 	c.Update(b, sql.NewRow("foo"))
-	assert.Equal(int32(1), c.Eval(b))
+	assert.Equal(int32(1), eval(t, c, b))
 
 	c.Update(b, sql.NewRow(nil))
-	assert.Equal(int32(1), c.Eval(b))
+	assert.Equal(int32(1), eval(t, c, b))
+}
+
+func TestCountDistinct(t *testing.T) {
+	assert := require.New(t)
+
+	c := NewCountDistinct(NewGetField(0, sql.String, "field", true))
+	assert.Equal("count(distinct field)", c.Name())
+
+	b := c.NewBuffer()
+	assert.Equal(int32(0), eval(t, c, b))
+
+	assert.NoError(c.Update(b, sql.NewRow("foo")))
+	assert.NoError(c.Update(b, sql.NewRow("bar")))
+	assert.NoError(c.Update(b, sql.NewRow("foo")))
+	assert.NoError(c.Update(b, sql.NewRow(nil)))
+	assert.Equal(int32(2), eval(t, c, b))
+
+	b2 := c.NewBuffer()
+	assert.NoError(c.Update(b2, sql.NewRow("bar")))
+	assert.NoError(c.Update(b2, sql.NewRow("baz")))
+	c.Merge(b, b2)
+	assert.Equal(int32(3), eval(t, c, b))
+
+	tc := c.TransformUp(func(e sql.Expression) sql.Expression { return e })
+	assert.Equal(c, tc)
 }
 
 func TestFirst_Name(t *testing.T) {
@@ -83,16 +108,16 @@ func TestFirst_Eval(t *testing.T) {
 
 	c := NewFirst(NewGetField(0, sql.Integer, "field", true))
 	b := c.NewBuffer()
-	assert.Nil(c.Eval(b))
+	assert.Nil(eval(t, c, b))
 
 	c.Update(b, sql.NewRow(int32(1)))
-	assert.Equal(int32(1), c.Eval(b))
+	assert.Equal(int32(1), eval(t, c, b))
 
 	c.Update(b, sql.NewRow(int32(2)))
-	assert.Equal(int32(1), c.Eval(b))
+	assert.Equal(int32(1), eval(t, c, b))
 
 	b2 := c.NewBuffer()
 	c.Update(b2, sql.NewRow(int32(2)))
 	c.Merge(b, b2)
-	assert.Equal(int32(1), c.Eval(b))
+	assert.Equal(int32(1), eval(t, c, b))
 }
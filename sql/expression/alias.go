@@ -15,7 +15,7 @@ func (e *Alias) Type() sql.Type {
 	return e.Child.Type()
 }
 
-func (e *Alias) Eval(row sql.Row) interface{} {
+func (e *Alias) Eval(row sql.Row) (interface{}, error) {
 	return e.Child.Eval(row)
 }
 
@@ -0,0 +1,271 @@
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// ErrDivisionByZero is returned when the divisor of a division or modulo
+// operation is zero.
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Arithmetic operators.
+const (
+	PlusOp   = "+"
+	MinusOp  = "-"
+	MultOp   = "*"
+	DivOp    = "/"
+	IntDivOp = "div"
+	ModOp    = "%"
+)
+
+// Arithmetic is an arithmetic operation between two numeric expressions.
+// Operands are promoted to the widest of their types (Integer, BigInteger and
+// Float, in that order) before the operation is performed. If any of them is
+// NULL, the result is NULL.
+type Arithmetic struct {
+	BinaryExpression
+	Op string
+}
+
+// NewArithmetic creates a new Arithmetic expression for the given operator.
+func NewArithmetic(left, right sql.Expression, op string) *Arithmetic {
+	return &Arithmetic{BinaryExpression{left, right}, op}
+}
+
+// NewPlus creates a new addition expression.
+func NewPlus(left, right sql.Expression) *Arithmetic {
+	return NewArithmetic(left, right, PlusOp)
+}
+
+// NewMinus creates a new subtraction expression.
+func NewMinus(left, right sql.Expression) *Arithmetic {
+	return NewArithmetic(left, right, MinusOp)
+}
+
+// NewMult creates a new multiplication expression.
+func NewMult(left, right sql.Expression) *Arithmetic {
+	return NewArithmetic(left, right, MultOp)
+}
+
+// NewDiv creates a new division expression. Its result is always a Float.
+func NewDiv(left, right sql.Expression) *Arithmetic {
+	return NewArithmetic(left, right, DivOp)
+}
+
+// NewIntDiv creates a new integer division expression.
+func NewIntDiv(left, right sql.Expression) *Arithmetic {
+	return NewArithmetic(left, right, IntDivOp)
+}
+
+// NewMod creates a new modulo expression.
+func NewMod(left, right sql.Expression) *Arithmetic {
+	return NewArithmetic(left, right, ModOp)
+}
+
+func (e *Arithmetic) Type() sql.Type {
+	switch e.Op {
+	case DivOp:
+		return sql.Float
+	case IntDivOp:
+		t := promoteNumeric(e.Left.Type(), e.Right.Type())
+		if t == sql.Float {
+			return sql.BigInteger
+		}
+
+		return t
+	default:
+		return promoteNumeric(e.Left.Type(), e.Right.Type())
+	}
+}
+
+func (e *Arithmetic) Name() string {
+	return e.Left.Name() + " " + e.Op + " " + e.Right.Name()
+}
+
+func (e *Arithmetic) Eval(row sql.Row) (interface{}, error) {
+	l, r, err := e.evalLeftAndRight(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	typ := promoteNumeric(e.Left.Type(), e.Right.Type())
+	if e.Op == DivOp {
+		typ = sql.Float
+	}
+
+	l, err = typ.Convert(l)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err = typ.Convert(r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case sql.Integer:
+		return e.evalInt32(l.(int32), r.(int32))
+	case sql.BigInteger:
+		return e.evalInt64(l.(int64), r.(int64))
+	case sql.Float:
+		return e.evalFloat64(l.(float64), r.(float64))
+	default:
+		return nil, fmt.Errorf("arithmetic on non-numeric type: %s", typ.Name())
+	}
+}
+
+func (e *Arithmetic) evalInt32(l, r int32) (interface{}, error) {
+	switch e.Op {
+	case PlusOp:
+		return l + r, nil
+	case MinusOp:
+		return l - r, nil
+	case MultOp:
+		return l * r, nil
+	case IntDivOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l / r, nil
+	case ModOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l % r, nil
+	default:
+		return nil, fmt.Errorf("unsupported arithmetic operator: %s", e.Op)
+	}
+}
+
+func (e *Arithmetic) evalInt64(l, r int64) (interface{}, error) {
+	switch e.Op {
+	case PlusOp:
+		return l + r, nil
+	case MinusOp:
+		return l - r, nil
+	case MultOp:
+		return l * r, nil
+	case IntDivOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l / r, nil
+	case ModOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l % r, nil
+	default:
+		return nil, fmt.Errorf("unsupported arithmetic operator: %s", e.Op)
+	}
+}
+
+func (e *Arithmetic) evalFloat64(l, r float64) (interface{}, error) {
+	switch e.Op {
+	case PlusOp:
+		return l + r, nil
+	case MinusOp:
+		return l - r, nil
+	case MultOp:
+		return l * r, nil
+	case DivOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return l / r, nil
+	case IntDivOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return int64(l / r), nil
+	case ModOp:
+		if r == 0 {
+			return nil, ErrDivisionByZero
+		}
+		return math.Mod(l, r), nil
+	default:
+		return nil, fmt.Errorf("unsupported arithmetic operator: %s", e.Op)
+	}
+}
+
+func (e *Arithmetic) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	lc := e.BinaryExpression.Left.TransformUp(f)
+	rc := e.BinaryExpression.Right.TransformUp(f)
+
+	return f(NewArithmetic(lc, rc, e.Op))
+}
+
+// UnaryMinus negates a numeric expression.
+type UnaryMinus struct {
+	UnaryExpression
+}
+
+// NewUnaryMinus creates a new UnaryMinus expression.
+func NewUnaryMinus(child sql.Expression) *UnaryMinus {
+	return &UnaryMinus{UnaryExpression{child}}
+}
+
+func (e *UnaryMinus) Type() sql.Type {
+	return e.Child.Type()
+}
+
+func (e *UnaryMinus) Name() string {
+	return "-" + e.Child.Name()
+}
+
+func (e *UnaryMinus) Eval(row sql.Row) (interface{}, error) {
+	v, err := e.Child.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n := v.(type) {
+	case nil:
+		return nil, nil
+	case int32:
+		return -n, nil
+	case int64:
+		return -n, nil
+	case float64:
+		return -n, nil
+	default:
+		return nil, fmt.Errorf("unary minus on non-numeric value: %#v", v)
+	}
+}
+
+func (e *UnaryMinus) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	c := e.UnaryExpression.Child.TransformUp(f)
+	return f(NewUnaryMinus(c))
+}
+
+// promoteNumeric returns the widest of the given numeric types. NULL operands
+// take the type of the other side.
+func promoteNumeric(a, b sql.Type) sql.Type {
+	if a == sql.Null {
+		a = b
+	}
+
+	if b == sql.Null {
+		b = a
+	}
+
+	switch {
+	case a == sql.Float || b == sql.Float:
+		return sql.Float
+	case a == sql.BigInteger || b == sql.BigInteger:
+		return sql.BigInteger
+	case a == sql.Integer && b == sql.Integer:
+		return sql.Integer
+	default:
+		return a
+	}
+}
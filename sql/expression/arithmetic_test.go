@@ -0,0 +1,131 @@
+package expression
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArithmetic(t *testing.T) {
+	var testCases = []struct {
+		name     string
+		expr     sql.Expression
+		typ      sql.Type
+		expected interface{}
+	}{
+		{
+			"integer plus integer",
+			NewPlus(
+				NewLiteral(int32(1), sql.Integer),
+				NewLiteral(int32(2), sql.Integer),
+			),
+			sql.Integer,
+			int32(3),
+		},
+		{
+			"integer minus biginteger",
+			NewMinus(
+				NewLiteral(int32(1), sql.Integer),
+				NewLiteral(int64(3), sql.BigInteger),
+			),
+			sql.BigInteger,
+			int64(-2),
+		},
+		{
+			"biginteger times float",
+			NewMult(
+				NewLiteral(int64(3), sql.BigInteger),
+				NewLiteral(float64(1.5), sql.Float),
+			),
+			sql.Float,
+			float64(4.5),
+		},
+		{
+			"division of integers",
+			NewDiv(
+				NewLiteral(int64(7), sql.BigInteger),
+				NewLiteral(int64(2), sql.BigInteger),
+			),
+			sql.Float,
+			float64(3.5),
+		},
+		{
+			"integer division",
+			NewIntDiv(
+				NewLiteral(int64(7), sql.BigInteger),
+				NewLiteral(int64(2), sql.BigInteger),
+			),
+			sql.BigInteger,
+			int64(3),
+		},
+		{
+			"modulo",
+			NewMod(
+				NewLiteral(int32(7), sql.Integer),
+				NewLiteral(int32(4), sql.Integer),
+			),
+			sql.Integer,
+			int32(3),
+		},
+		{
+			"null operand",
+			NewPlus(
+				NewLiteral(nil, sql.Null),
+				NewLiteral(int64(2), sql.BigInteger),
+			),
+			sql.BigInteger,
+			nil,
+		},
+		{
+			"unary minus",
+			NewUnaryMinus(NewLiteral(float64(2.5), sql.Float)),
+			sql.Float,
+			float64(-2.5),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			require.Equal(tt.typ, tt.expr.Type())
+			require.Equal(tt.expected, eval(t, tt.expr, nil))
+		})
+	}
+}
+
+func TestArithmetic_DivisionByZero(t *testing.T) {
+	require := require.New(t)
+
+	for _, e := range []sql.Expression{
+		NewDiv(
+			NewLiteral(int64(1), sql.BigInteger),
+			NewLiteral(int64(0), sql.BigInteger),
+		),
+		NewIntDiv(
+			NewLiteral(int32(1), sql.Integer),
+			NewLiteral(int32(0), sql.Integer),
+		),
+		NewMod(
+			NewLiteral(float64(1), sql.Float),
+			NewLiteral(float64(0), sql.Float),
+		),
+	} {
+		_, err := e.Eval(nil)
+		require.Equal(ErrDivisionByZero, err)
+	}
+}
+
+func TestArithmetic_GetField(t *testing.T) {
+	require := require.New(t)
+
+	e := NewMult(
+		NewGetField(0, sql.BigInteger, "price", true),
+		NewGetField(1, sql.Integer, "qty", true),
+	)
+	require.Equal("price * qty", e.Name())
+	require.Equal(sql.BigInteger, e.Type())
+	require.Equal(int64(20), eval(t, e, sql.NewRow(int64(10), int32(2))))
+	require.Nil(eval(t, e, sql.NewRow(nil, int32(2))))
+}
@@ -14,8 +14,17 @@ func (e Not) Type() sql.Type {
 	return sql.Boolean
 }
 
-func (e Not) Eval(row sql.Row) interface{} {
-	return !e.Child.Eval(row).(bool)
+func (e Not) Eval(row sql.Row) (interface{}, error) {
+	v, err := e.Child.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	return !v.(bool), nil
 }
 
 func (e Not) Name() string {
@@ -28,3 +37,107 @@ func (e *Not) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression
 
 	return f(n)
 }
+
+// And checks whether both of its children are true, following SQL
+// three-valued logic: it's false if any of them is false, NULL if any of them
+// is NULL and true otherwise.
+type And struct {
+	BinaryExpression
+}
+
+func NewAnd(left, right sql.Expression) *And {
+	return &And{BinaryExpression{left, right}}
+}
+
+func (e And) Type() sql.Type {
+	return sql.Boolean
+}
+
+func (e And) Eval(row sql.Row) (interface{}, error) {
+	l, err := e.Left.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == false {
+		return false, nil
+	}
+
+	r, err := e.Right.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if r == false {
+		return false, nil
+	}
+
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	return true, nil
+}
+
+func (e And) Name() string {
+	return e.Left.Name() + " AND " + e.Right.Name()
+}
+
+func (e *And) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	lc := e.BinaryExpression.Left.TransformUp(f)
+	rc := e.BinaryExpression.Right.TransformUp(f)
+
+	return f(NewAnd(lc, rc))
+}
+
+// Or checks whether any of its children is true, following SQL three-valued
+// logic: it's true if any of them is true, NULL if any of them is NULL and
+// false otherwise.
+type Or struct {
+	BinaryExpression
+}
+
+func NewOr(left, right sql.Expression) *Or {
+	return &Or{BinaryExpression{left, right}}
+}
+
+func (e Or) Type() sql.Type {
+	return sql.Boolean
+}
+
+func (e Or) Eval(row sql.Row) (interface{}, error) {
+	l, err := e.Left.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == true {
+		return true, nil
+	}
+
+	r, err := e.Right.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	if r == true {
+		return true, nil
+	}
+
+	if l == nil || r == nil {
+		return nil, nil
+	}
+
+	return false, nil
+}
+
+func (e Or) Name() string {
+	return e.Left.Name() + " OR " + e.Right.Name()
+}
+
+func (e *Or) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	lc := e.BinaryExpression.Left.TransformUp(f)
+	rc := e.BinaryExpression.Right.TransformUp(f)
+
+	return f(NewOr(lc, rc))
+}
@@ -0,0 +1,75 @@
+package expression
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNot(t *testing.T) {
+	require := require.New(t)
+
+	e := NewNot(NewGetField(0, sql.Boolean, "foo", true))
+	require.Equal(sql.Boolean, e.Type())
+	require.Equal(false, eval(t, e, sql.NewRow(true)))
+	require.Equal(true, eval(t, e, sql.NewRow(false)))
+	require.Nil(eval(t, e, sql.NewRow(nil)))
+}
+
+func TestAnd(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		left, right interface{}
+		expected    interface{}
+	}{
+		{"left is true, right is false", true, false, false},
+		{"left is true, right is null", true, nil, nil},
+		{"left is false, right is true", false, true, false},
+		{"left is false, right is null", false, nil, false},
+		{"left is null, right is false", nil, false, false},
+		{"left is null, right is null", nil, nil, nil},
+		{"both true", true, true, true},
+		{"both false", false, false, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			e := NewAnd(
+				NewGetField(0, sql.Boolean, "l", true),
+				NewGetField(1, sql.Boolean, "r", true),
+			)
+			require.Equal(tt.expected, eval(t, e, sql.NewRow(tt.left, tt.right)))
+		})
+	}
+}
+
+func TestOr(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		left, right interface{}
+		expected    interface{}
+	}{
+		{"left is true, right is false", true, false, true},
+		{"left is true, right is null", true, nil, true},
+		{"left is false, right is true", false, true, true},
+		{"left is false, right is null", false, nil, nil},
+		{"left is null, right is true", nil, true, true},
+		{"left is null, right is null", nil, nil, nil},
+		{"both true", true, true, true},
+		{"both false", false, false, false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			e := NewOr(
+				NewGetField(0, sql.Boolean, "l", true),
+				NewGetField(1, sql.Boolean, "r", true),
+			)
+			require.Equal(tt.expected, eval(t, e, sql.NewRow(tt.left, tt.right)))
+		})
+	}
+}
@@ -27,6 +27,22 @@ func (p BinaryExpression) IsNullable() bool {
 	return p.Left.IsNullable() || p.Right.IsNullable()
 }
 
+func (p BinaryExpression) evalLeftAndRight(row sql.Row) (interface{},
+	interface{}, error) {
+
+	l, err := p.Left.Eval(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := p.Right.Eval(row)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return l, r, nil
+}
+
 var defaultFunctions = map[string]interface{}{
 	"count": NewCount,
 	"first": NewFirst,
@@ -0,0 +1,15 @@
+package expression
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func eval(t *testing.T, e sql.Expression, row sql.Row) interface{} {
+	v, err := e.Eval(row)
+	require.NoError(t, err)
+	return v
+}
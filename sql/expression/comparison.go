@@ -20,6 +20,60 @@ func (*Comparison) Name() string {
 	return ""
 }
 
+// compare evaluates both sides of the comparison and compares them. Numeric
+// values are promoted to the widest of their types before being compared. It
+// returns false if any of them is NULL.
+func (c Comparison) compare(row sql.Row) (int, bool, error) {
+	l, r, err := c.evalLeftAndRight(row)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if l == nil || r == nil {
+		return 0, false, nil
+	}
+
+	typ := c.ChildType
+	lt, rt := c.Left.Type(), c.Right.Type()
+	if lt != rt && isNumeric(lt) && isNumeric(rt) {
+		typ = ComparisonType(lt, rt)
+
+		l, err = typ.Convert(l)
+		if err != nil {
+			return 0, false, err
+		}
+
+		r, err = typ.Convert(r)
+		if err != nil {
+			return 0, false, err
+		}
+	} else if lt != rt {
+		// Values bound to placeholders, for example, don't necessarily
+		// have the same type as the column they are compared with.
+		r, err = typ.Convert(r)
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	return typ.Compare(l, r), true, nil
+}
+
+// ComparisonType returns the type values of the given types are converted to
+// before comparing them: the widest of both if they are numeric, or the left
+// one otherwise.
+func ComparisonType(left, right sql.Type) sql.Type {
+	if left != right && isNumeric(left) && isNumeric(right) {
+		return promoteNumeric(left, right)
+	}
+
+	return left
+}
+
+func isNumeric(t sql.Type) bool {
+	return t == sql.Integer || t == sql.BigInteger || t == sql.Float
+}
+
 type Equals struct {
 	Comparison
 }
@@ -30,14 +84,13 @@ func NewEquals(left sql.Expression, right sql.Expression) *Equals {
 	return &Equals{Comparison{BinaryExpression{left, right}, left.Type()}}
 }
 
-func (e Equals) Eval(row sql.Row) interface{} {
-	a := e.Left.Eval(row)
-	b := e.Right.Eval(row)
-	if a == nil || b == nil {
-		return nil
+func (e Equals) Eval(row sql.Row) (interface{}, error) {
+	cmp, ok, err := e.compare(row)
+	if err != nil || !ok {
+		return nil, err
 	}
 
-	return e.ChildType.Compare(a, b) == 0
+	return cmp == 0, nil
 }
 
 func (c *Equals) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -61,26 +114,29 @@ func NewRegexp(left sql.Expression, right sql.Expression) *Regexp {
 	return &Regexp{Comparison{BinaryExpression{left, right}, left.Type()}}
 }
 
-func (e Regexp) Eval(row sql.Row) interface{} {
-	l := e.Left.Eval(row)
-	r := e.Right.Eval(row)
+func (e Regexp) Eval(row sql.Row) (interface{}, error) {
+	l, r, err := e.evalLeftAndRight(row)
+	if err != nil {
+		return nil, err
+	}
+
 	if l == nil || r == nil {
-		return nil
+		return nil, nil
 	}
 
 	sl, okl := l.(string)
 	sr, okr := r.(string)
 
 	if !okl || !okr {
-		return e.ChildType.Compare(l, r) == 0
+		return e.ChildType.Compare(l, r) == 0, nil
 	}
 
 	reg, err := regexp.Compile(sr)
 	if err != nil {
-		return false
+		return false, nil
 	}
 
-	return reg.MatchString(sl)
+	return reg.MatchString(sl), nil
 }
 
 func (c *Regexp) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -104,14 +160,13 @@ func NewGreaterThan(left sql.Expression, right sql.Expression) *GreaterThan {
 	return &GreaterThan{Comparison{BinaryExpression{left, right}, left.Type()}}
 }
 
-func (e GreaterThan) Eval(row sql.Row) interface{} {
-	a := e.Left.Eval(row)
-	b := e.Right.Eval(row)
-	if a == nil || b == nil {
-		return nil
+func (e GreaterThan) Eval(row sql.Row) (interface{}, error) {
+	cmp, ok, err := e.compare(row)
+	if err != nil || !ok {
+		return nil, err
 	}
 
-	return e.ChildType.Compare(a, b) == 1
+	return cmp == 1, nil
 }
 
 func (c *GreaterThan) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -131,14 +186,13 @@ func NewLessThan(left sql.Expression, right sql.Expression) *LessThan {
 	return &LessThan{Comparison{BinaryExpression{left, right}, left.Type()}}
 }
 
-func (e LessThan) Eval(row sql.Row) interface{} {
-	a := e.Left.Eval(row)
-	b := e.Right.Eval(row)
-	if a == nil || b == nil {
-		return nil
+func (e LessThan) Eval(row sql.Row) (interface{}, error) {
+	cmp, ok, err := e.compare(row)
+	if err != nil || !ok {
+		return nil, err
 	}
 
-	return e.ChildType.Compare(a, b) == -1
+	return cmp == -1, nil
 }
 
 func (c *LessThan) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -158,14 +212,13 @@ func NewGreaterThanOrEqual(left sql.Expression, right sql.Expression) *GreaterTh
 	return &GreaterThanOrEqual{Comparison{BinaryExpression{left, right}, left.Type()}}
 }
 
-func (e GreaterThanOrEqual) Eval(row sql.Row) interface{} {
-	a := e.Left.Eval(row)
-	b := e.Right.Eval(row)
-	if a == nil || b == nil {
-		return nil
+func (e GreaterThanOrEqual) Eval(row sql.Row) (interface{}, error) {
+	cmp, ok, err := e.compare(row)
+	if err != nil || !ok {
+		return nil, err
 	}
 
-	return e.ChildType.Compare(a, b) > -1
+	return cmp > -1, nil
 }
 
 func (c *GreaterThanOrEqual) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -185,14 +238,13 @@ func NewLessThanOrEqual(left sql.Expression, right sql.Expression) *LessThanOrEq
 	return &LessThanOrEqual{Comparison{BinaryExpression{left, right}, left.Type()}}
 }
 
-func (e LessThanOrEqual) Eval(row sql.Row) interface{} {
-	a := e.Left.Eval(row)
-	b := e.Right.Eval(row)
-	if a == nil || b == nil {
-		return nil
+func (e LessThanOrEqual) Eval(row sql.Row) (interface{}, error) {
+	cmp, ok, err := e.compare(row)
+	if err != nil || !ok {
+		return nil, err
 	}
 
-	return e.ChildType.Compare(a, b) < 1
+	return cmp < 1, nil
 }
 
 func (c *LessThanOrEqual) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -2,6 +2,7 @@ package expression
 
 import (
 	"testing"
+	"time"
 
 	"gopkg.in/sqle/sqle.v0/sql"
 
@@ -104,7 +105,7 @@ func TestComparisons_Equals(t *testing.T) {
 			for _, pair := range cases {
 				row := sql.NewRow(pair[0], pair[1])
 				assert.NotNil(row)
-				cmp := eq.Eval(row)
+				cmp := eval(t, eq, row)
 				if cmpResult == testEqual {
 					assert.Equal(true, cmp)
 				} else if cmpResult == testNil {
@@ -131,7 +132,7 @@ func TestComparisons_LessThan(t *testing.T) {
 			for _, pair := range cases {
 				row := sql.NewRow(pair[0], pair[1])
 				assert.NotNil(row)
-				cmp := eq.Eval(row)
+				cmp := eval(t, eq, row)
 				if cmpResult == testLess {
 					assert.Equal(true, cmp, "%v < %v", pair[0], pair[1])
 				} else if cmpResult == testNil {
@@ -158,7 +159,7 @@ func TestComparisons_GreaterThan(t *testing.T) {
 			for _, pair := range cases {
 				row := sql.NewRow(pair[0], pair[1])
 				assert.NotNil(row)
-				cmp := eq.Eval(row)
+				cmp := eval(t, eq, row)
 				if cmpResult == testGreater {
 					assert.Equal(true, cmp)
 				} else if cmpResult == testNil {
@@ -185,7 +186,7 @@ func TestComparisons_Regexp(t *testing.T) {
 			for _, pair := range cases {
 				row := sql.NewRow(pair[0], pair[1])
 				assert.NotNil(row)
-				cmp := eq.Eval(row)
+				cmp := eval(t, eq, row)
 				if cmpResult == testRegexp {
 					assert.Equal(true, cmp)
 				} else if cmpResult == testNil {
@@ -197,3 +198,36 @@ func TestComparisons_Regexp(t *testing.T) {
 		}
 	}
 }
+
+func TestComparisons_MixedNumericTypes(t *testing.T) {
+	assert := require.New(t)
+
+	i := NewGetField(0, sql.Integer, "i", true)
+	b := NewGetField(1, sql.BigInteger, "b", true)
+	f := NewGetField(2, sql.Float, "f", true)
+	row := sql.NewRow(int32(2), int64(3), float64(2.5))
+
+	assert.Equal(true, eval(t, NewLessThan(i, b), row))
+	assert.Equal(true, eval(t, NewGreaterThan(b, i), row))
+	assert.Equal(true, eval(t, NewLessThanOrEqual(i, f), row))
+	assert.Equal(true, eval(t, NewGreaterThanOrEqual(b, f), row))
+	assert.Equal(false, eval(t, NewEquals(i, b), row))
+	assert.Equal(true, eval(t, NewEquals(i, NewLiteral(int64(2), sql.BigInteger)), row))
+	assert.Nil(eval(t, NewEquals(i, NewLiteral(nil, sql.Null)), row))
+}
+
+func TestComparisons_ConvertRight(t *testing.T) {
+	assert := require.New(t)
+
+	ts := NewGetField(0, sql.TimestampWithTimezone, "ts", true)
+	row := sql.NewRow(time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	assert.Equal(true, eval(t, NewEquals(
+		ts,
+		NewLiteral("2017-01-02 03:04:05.000000", sql.String),
+	), row))
+	assert.Equal(true, eval(t, NewLessThan(
+		ts,
+		NewLiteral("2018-01-01 00:00:00.000000", sql.String),
+	), row))
+}
@@ -3,6 +3,7 @@ package expression
 import "gopkg.in/sqle/sqle.v0/sql"
 
 type GetField struct {
+	table      string
 	fieldIndex int
 	fieldName  string
 	fieldType  sql.Type
@@ -10,7 +11,16 @@ type GetField struct {
 }
 
 func NewGetField(index int, fieldType sql.Type, fieldName string, nullable bool) *GetField {
+	return NewGetFieldWithTable(index, fieldType, "", fieldName, nullable)
+}
+
+// NewGetFieldWithTable creates a GetField expression for a field of the given
+// table.
+func NewGetFieldWithTable(index int, fieldType sql.Type, table string,
+	fieldName string, nullable bool) *GetField {
+
 	return &GetField{
+		table:      table,
 		fieldIndex: index,
 		fieldType:  fieldType,
 		fieldName:  fieldName,
@@ -18,6 +28,16 @@ func NewGetField(index int, fieldType sql.Type, fieldName string, nullable bool)
 	}
 }
 
+// Table returns the name of the table or alias the field belongs to, if any.
+func (p GetField) Table() string {
+	return p.table
+}
+
+// Index returns the index of the field in the row.
+func (p GetField) Index() int {
+	return p.fieldIndex
+}
+
 func (p GetField) Resolved() bool {
 	return true
 }
@@ -30,8 +50,8 @@ func (p GetField) Type() sql.Type {
 	return p.fieldType
 }
 
-func (p GetField) Eval(row sql.Row) interface{} {
-	return row[p.fieldIndex]
+func (p GetField) Eval(row sql.Row) (interface{}, error) {
+	return row[p.fieldIndex], nil
 }
 
 func (p GetField) Name() string {
@@ -18,8 +18,13 @@ func (e *IsNull) IsNullable() bool {
 	return false
 }
 
-func (e *IsNull) Eval(row sql.Row) interface{} {
-	return e.Child.Eval(row) == nil
+func (e *IsNull) Eval(row sql.Row) (interface{}, error) {
+	v, err := e.Child.Eval(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return v == nil, nil
 }
 
 func (e *IsNull) Name() string {
@@ -15,6 +15,6 @@ func TestIsNull(t *testing.T) {
 	e := NewIsNull(get0)
 	require.Equal(sql.Boolean, e.Type())
 	require.Equal(false, e.IsNullable())
-	require.Equal(true, e.Eval(sql.NewRow(nil)))
-	require.Equal(false, e.Eval(sql.NewRow("")))
+	require.Equal(true, eval(t, e, sql.NewRow(nil)))
+	require.Equal(false, eval(t, e, sql.NewRow("")))
 }
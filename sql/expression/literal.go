@@ -28,8 +28,8 @@ func (p Literal) Type() sql.Type {
 	return p.fieldType
 }
 
-func (p Literal) Eval(row sql.Row) interface{} {
-	return p.value
+func (p Literal) Eval(row sql.Row) (interface{}, error) {
+	return p.value, nil
 }
 
 func (p Literal) Name() string {
@@ -0,0 +1,55 @@
+package expression
+
+import (
+	"errors"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// ErrUnboundPlaceholder is returned when a placeholder is evaluated before a
+// value has been bound to it.
+var ErrUnboundPlaceholder = errors.New("placeholder has no value bound")
+
+// Placeholder is a parameter of a prepared statement, written as ? or :name
+// in the query. It must be replaced by a value before being evaluated.
+type Placeholder struct {
+	name string
+}
+
+// NewPlaceholder creates a new Placeholder with the given name. Positional
+// placeholders are named v1, v2, ... in order of appearance.
+func NewPlaceholder(name string) *Placeholder {
+	return &Placeholder{name}
+}
+
+// Placeholder returns the name of the placeholder, without the leading colon.
+func (p Placeholder) Placeholder() string {
+	return p.name
+}
+
+// Resolved implements the Expression interface. Placeholders are considered
+// resolved so the plan containing them can be analyzed before binding values.
+func (Placeholder) Resolved() bool {
+	return true
+}
+
+func (Placeholder) IsNullable() bool {
+	return true
+}
+
+func (Placeholder) Type() sql.Type {
+	return sql.Null
+}
+
+func (Placeholder) Eval(row sql.Row) (interface{}, error) {
+	return nil, ErrUnboundPlaceholder
+}
+
+func (p Placeholder) Name() string {
+	return ":" + p.name
+}
+
+func (p *Placeholder) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	n := *p
+	return f(&n)
+}
@@ -0,0 +1,19 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholder(t *testing.T) {
+	require := require.New(t)
+
+	p := NewPlaceholder("name")
+	require.Equal("name", p.Placeholder())
+	require.Equal(":name", p.Name())
+	require.True(p.Resolved())
+
+	_, err := p.Eval(nil)
+	require.Equal(ErrUnboundPlaceholder, err)
+}
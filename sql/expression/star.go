@@ -2,13 +2,22 @@ package expression
 
 import "gopkg.in/sqle/sqle.v0/sql"
 
+// Star is the * in a projection. If it has a table, it only refers to the
+// columns of that table or alias.
 type Star struct {
+	Table string
 }
 
 func NewStar() *Star {
 	return &Star{}
 }
 
+// NewQualifiedStar creates a new Star referring to the columns of the given
+// table or alias.
+func NewQualifiedStar(table string) *Star {
+	return &Star{table}
+}
+
 func (Star) Resolved() bool {
 	return false
 }
@@ -21,14 +30,19 @@ func (Star) Type() sql.Type {
 	return sql.String //FIXME
 }
 
-func (Star) Name() string {
+func (s Star) Name() string {
+	if s.Table != "" {
+		return s.Table + ".*"
+	}
+
 	return "*"
 }
 
-func (Star) Eval(r sql.Row) interface{} {
-	return "FAIL" //FIXME
+func (Star) Eval(r sql.Row) (interface{}, error) {
+	return "FAIL", nil //FIXME
 }
 
 func (s *Star) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
-	return f(s)
+	n := *s
+	return f(&n)
 }
@@ -14,3 +14,10 @@ func TestStar(t *testing.T) {
 	assert.NotNil(e)
 	assert.Equal("*", e.Name())
 }
+
+func TestQualifiedStar(t *testing.T) {
+	assert := assert.New(t)
+	var e sql.Expression = NewQualifiedStar("foo")
+	assert.Equal("foo.*", e.Name())
+	assert.False(e.Resolved())
+}
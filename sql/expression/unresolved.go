@@ -1,13 +1,30 @@
 package expression
 
-import "gopkg.in/sqle/sqle.v0/sql"
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
 
 type UnresolvedColumn struct {
-	name string
+	name  string
+	table string
 }
 
 func NewUnresolvedColumn(name string) *UnresolvedColumn {
-	return &UnresolvedColumn{name}
+	return &UnresolvedColumn{name: name}
+}
+
+// NewUnresolvedQualifiedColumn creates a new UnresolvedColumn qualified with
+// the name of the table or alias it belongs to.
+func NewUnresolvedQualifiedColumn(table, name string) *UnresolvedColumn {
+	return &UnresolvedColumn{name: name, table: table}
+}
+
+// Table returns the table or alias qualifying the column, or an empty string
+// if it is not qualified.
+func (c UnresolvedColumn) Table() string {
+	return c.table
 }
 
 func (UnresolvedColumn) Resolved() bool {
@@ -26,8 +43,8 @@ func (c UnresolvedColumn) Name() string {
 	return c.name
 }
 
-func (UnresolvedColumn) Eval(r sql.Row) interface{} {
-	return "FAIL" //FIXME
+func (UnresolvedColumn) Eval(r sql.Row) (interface{}, error) {
+	return "FAIL", nil //FIXME
 }
 
 func (p *UnresolvedColumn) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -35,15 +52,62 @@ func (p *UnresolvedColumn) TransformUp(f func(sql.Expression) sql.Expression) sq
 	return f(&n)
 }
 
+// UnresolvedValuesColumn is a reference to the value being inserted in a
+// column, written as VALUES(col) in INSERT ... ON DUPLICATE KEY UPDATE.
+type UnresolvedValuesColumn struct {
+	name string
+}
+
+// NewUnresolvedValuesColumn creates a new UnresolvedValuesColumn for the
+// column with the given name.
+func NewUnresolvedValuesColumn(name string) *UnresolvedValuesColumn {
+	return &UnresolvedValuesColumn{name}
+}
+
+func (UnresolvedValuesColumn) Resolved() bool {
+	return false
+}
+
+func (UnresolvedValuesColumn) IsNullable() bool {
+	return true
+}
+
+func (UnresolvedValuesColumn) Type() sql.Type {
+	return sql.Null
+}
+
+func (c UnresolvedValuesColumn) Name() string {
+	return c.name
+}
+
+func (UnresolvedValuesColumn) Eval(r sql.Row) (interface{}, error) {
+	return nil, fmt.Errorf("unresolved expression VALUES(...)")
+}
+
+func (p *UnresolvedValuesColumn) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
+	n := *p
+	return f(&n)
+}
+
 type UnresolvedFunction struct {
 	name        string
 	IsAggregate bool
-	Children    []sql.Expression
+	// Distinct is true if the function must only take into account
+	// distinct values, as in COUNT(DISTINCT x).
+	Distinct bool
+	Children []sql.Expression
 }
 
 func NewUnresolvedFunction(name string, agg bool,
 	children ...sql.Expression) *UnresolvedFunction {
-	return &UnresolvedFunction{name, agg, children}
+	return &UnresolvedFunction{name, agg, false, children}
+}
+
+// NewUnresolvedDistinctFunction creates a new UnresolvedFunction that only
+// takes into account distinct values of its arguments.
+func NewUnresolvedDistinctFunction(name string,
+	children ...sql.Expression) *UnresolvedFunction {
+	return &UnresolvedFunction{name, true, true, children}
 }
 
 func (UnresolvedFunction) Resolved() bool {
@@ -62,8 +126,8 @@ func (c UnresolvedFunction) Name() string {
 	return c.name
 }
 
-func (UnresolvedFunction) Eval(r sql.Row) interface{} {
-	return "FAIL" //FIXME
+func (UnresolvedFunction) Eval(r sql.Row) (interface{}, error) {
+	return "FAIL", nil //FIXME
 }
 
 func (p *UnresolvedFunction) TransformUp(f func(sql.Expression) sql.Expression) sql.Expression {
@@ -72,5 +136,5 @@ func (p *UnresolvedFunction) TransformUp(f func(sql.Expression) sql.Expression)
 		rc = append(rc, f(c))
 	}
 
-	return f(NewUnresolvedFunction(p.name, p.IsAggregate, rc...))
+	return f(&UnresolvedFunction{p.name, p.IsAggregate, p.Distinct, rc})
 }
@@ -17,3 +17,25 @@ func TestUnresolvedExpression(t *testing.T) {
 	o = NewNot(e)
 	assert.NotNil(o)
 }
+
+func TestUnresolvedQualifiedColumn(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewUnresolvedQualifiedColumn("t", "col")
+	assert.Equal("t", c.Table())
+	assert.Equal("col", c.Name())
+	assert.False(c.Resolved())
+
+	assert.Equal("", NewUnresolvedColumn("col").Table())
+}
+
+func TestUnresolvedValuesColumn(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewUnresolvedValuesColumn("col")
+	assert.Equal("col", c.Name())
+	assert.False(c.Resolved())
+
+	_, err := c.Eval(nil)
+	assert.Error(err)
+}
@@ -17,6 +17,24 @@ const (
 	showTables = "SHOW TABLES"
 )
 
+// ALTER TABLE statements are matched with regular expressions, since the
+// parser discards everything but the name of the table.
+var (
+	alterTable   = regexp.MustCompile(`(?is)^alter\s+table\s+(\S+)\s+(.*)$`)
+	addColumn    = regexp.MustCompile(`(?is)^add\s+(?:column\s+)?(.+)$`)
+	dropColumn   = regexp.MustCompile(`(?is)^drop\s+(?:column\s+)?(\S+)$`)
+	renameColumn = regexp.MustCompile(`(?is)^rename\s+column\s+(\S+)\s+to\s+(\S+)$`)
+)
+
+// createIndex matches CREATE INDEX statements, which the parser also
+// discards.
+var createIndex = regexp.MustCompile(`(?is)^create\s+(\w+\s+)?index\s+(\S+)` +
+	`(?:\s+using\s+(\w+))?\s+on\s+(\S+?)\s*\(([^)]*)\)(?:\s+using\s+(\w+))?$`)
+
+// createTableIfNotExists matches CREATE TABLE statements with IF NOT EXISTS,
+// since the parser discards the clause.
+var createTableIfNotExists = regexp.MustCompile(`^create\s+table\s+if\s+not\s+exists\s`)
+
 func errUnsupported(n sqlparser.SQLNode) error {
 	return fmt.Errorf("unsupported syntax: %#v", n)
 }
@@ -40,12 +58,30 @@ func Parse(s string) (sql.Node, error) {
 		return plan.NewDescribe(plan.NewUnresolvedTable(t[1])), nil
 	}
 
+	if m := alterTable.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+		return convertAlterTable(unquote(m[1]), m[2])
+	}
+
+	if m := createIndex.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+		return convertCreateIndex(m)
+	}
+
 	stmt, err := sqlparser.Parse(s)
 	if err != nil {
 		return nil, err
 	}
 
-	return convert(stmt)
+	node, err := convert(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	lower := strings.ToLower(strings.TrimSpace(s))
+	if ct, ok := node.(*plan.CreateTable); ok && createTableIfNotExists.MatchString(lower) {
+		ct.IfNotExists = true
+	}
+
+	return node, nil
 }
 
 func convert(stmt sqlparser.Statement) (sql.Node, error) {
@@ -56,6 +92,12 @@ func convert(stmt sqlparser.Statement) (sql.Node, error) {
 		return convertSelect(n)
 	case *sqlparser.Insert:
 		return convertInsert(n)
+	case *sqlparser.Update:
+		return convertUpdate(n)
+	case *sqlparser.Delete:
+		return convertDelete(n)
+	case *sqlparser.DDL:
+		return convertDDL(n)
 	}
 }
 
@@ -67,14 +109,6 @@ func convertSelect(s *sqlparser.Select) (sql.Node, error) {
 		return nil, err
 	}
 
-	if s.Distinct != "" {
-		return nil, errUnsupportedFeature("DISTINCT")
-	}
-
-	if s.Having != nil {
-		return nil, errUnsupportedFeature("HAVING")
-	}
-
 	if s.Where != nil {
 		node, err = whereToFilter(s.Where, node)
 		if err != nil {
@@ -94,8 +128,27 @@ func convertSelect(s *sqlparser.Select) (sql.Node, error) {
 		return nil, err
 	}
 
+	if s.Having != nil {
+		cond, err := exprToExpression(s.Having.Expr)
+		if err != nil {
+			return nil, err
+		}
+
+		node = plan.NewHaving(cond, node)
+	}
+
+	if s.Distinct != "" {
+		node = plan.NewDistinct(node)
+	}
+
+	if s.Limit != nil && s.Limit.Offset != nil {
+		node, err = offsetToOffset(s.Limit.Offset, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if s.Limit != nil {
-		//TODO: Add support for offset
 		node, err = limitToLimit(s.Limit.Rowcount, node)
 		if err != nil {
 			return nil, err
@@ -106,26 +159,387 @@ func convertSelect(s *sqlparser.Select) (sql.Node, error) {
 }
 
 func convertInsert(i *sqlparser.Insert) (sql.Node, error) {
-	if len(i.OnDup) > 0 {
-		return nil, errUnsupportedFeature("ON DUPLICATE KEY")
+	if len(i.Partitions) > 0 {
+		return nil, errUnsupportedFeature("PARTITION")
+	}
+
+	src, err := insertRowsToNode(i.Rows)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(i.Ignore) > 0 {
-		return nil, errUnsupported(i)
+	dst := plan.NewUnresolvedTable(i.Table.Name.String())
+	cols := columnsToStrings(i.Columns)
+	switch {
+	case i.Action == sqlparser.ReplaceStr:
+		return plan.NewReplaceInto(dst, src, cols), nil
+	case len(i.OnDup) > 0:
+		fields, err := updateExprsToFields(sqlparser.UpdateExprs(i.OnDup))
+		if err != nil {
+			return nil, err
+		}
+
+		return plan.NewInsertOnDuplicateKeyUpdate(dst, src, cols, fields), nil
+	case len(i.Ignore) > 0:
+		return plan.NewInsertIgnore(dst, src, cols), nil
+	default:
+		return plan.NewInsertInto(dst, src, cols), nil
 	}
+}
 
-	src, err := insertRowsToNode(i.Rows)
+func convertUpdate(u *sqlparser.Update) (sql.Node, error) {
+	if len(u.TableExprs) != 1 {
+		return nil, errUnsupportedFeature("UPDATE of multiple tables")
+	}
+
+	if _, ok := u.TableExprs[0].(*sqlparser.AliasedTableExpr); !ok {
+		return nil, errUnsupportedFeature("UPDATE of joins")
+	}
+
+	node, err := tableExprToTable(u.TableExprs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	node, err = modifiedRowsToNode(u.Where, u.OrderBy, u.Limit, node)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := updateExprsToFields(u.Exprs)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewUpdate(fields, node), nil
+}
+
+func updateExprsToFields(exprs sqlparser.UpdateExprs) ([]plan.UpdateField, error) {
+	fields := make([]plan.UpdateField, len(exprs))
+	for i, ue := range exprs {
+		col, err := exprToExpression(ue.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := exprToExpression(ue.Expr)
+		if err != nil {
+			return nil, err
+		}
+
+		fields[i] = plan.UpdateField{Column: col, Value: val}
+	}
+
+	return fields, nil
+}
+
+func convertDelete(d *sqlparser.Delete) (sql.Node, error) {
+	if len(d.Targets) > 0 || len(d.TableExprs) != 1 {
+		return nil, errUnsupportedFeature("DELETE of multiple tables")
+	}
+
+	if _, ok := d.TableExprs[0].(*sqlparser.AliasedTableExpr); !ok {
+		return nil, errUnsupportedFeature("DELETE of joins")
+	}
+
+	if len(d.Partitions) > 0 {
+		return nil, errUnsupportedFeature("PARTITION")
+	}
+
+	node, err := tableExprToTable(d.TableExprs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	node, err = modifiedRowsToNode(d.Where, d.OrderBy, d.Limit, node)
 	if err != nil {
 		return nil, err
 	}
 
-	return plan.NewInsertInto(
-		plan.NewUnresolvedTable(i.Table.Name.String()),
-		src,
-		columnsToStrings(i.Columns),
+	return plan.NewDelete(node), nil
+}
+
+func convertDDL(d *sqlparser.DDL) (sql.Node, error) {
+	switch d.Action {
+	case sqlparser.CreateStr:
+		return convertCreateTable(d)
+	case sqlparser.DropStr:
+		return plan.NewDropTable(
+			&sql.UnresolvedDatabase{},
+			d.Table.Name.String(),
+			d.IfExists,
+		), nil
+	case sqlparser.TruncateStr:
+		// TRUNCATE TABLE is a DELETE without conditions.
+		return plan.NewDelete(plan.NewUnresolvedTable(d.Table.Name.String())), nil
+	default:
+		return nil, errUnsupported(d)
+	}
+}
+
+func convertAlterTable(table, op string) (sql.Node, error) {
+	t := plan.NewUnresolvedTable(table)
+	if m := renameColumn.FindStringSubmatch(op); m != nil {
+		return plan.NewRenameColumn(unquote(m[1]), unquote(m[2]), t), nil
+	}
+
+	if m := dropColumn.FindStringSubmatch(op); m != nil {
+		return plan.NewDropColumn(unquote(m[1]), t), nil
+	}
+
+	if m := addColumn.FindStringSubmatch(op); m != nil {
+		// The definition of the column is parsed as the only column of a
+		// CREATE TABLE statement.
+		stmt, err := sqlparser.Parse("CREATE TABLE t (" + m[1] + ")")
+		if err != nil {
+			return nil, err
+		}
+
+		d, ok := stmt.(*sqlparser.DDL)
+		if !ok || d.TableSpec == nil ||
+			len(d.TableSpec.Columns) != 1 || len(d.TableSpec.Indexes) != 0 {
+			return nil, errUnsupportedFeature("ALTER TABLE ADD " + m[1])
+		}
+
+		col, err := columnDefinitionToColumn(d.TableSpec.Columns[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return plan.NewAddColumn(col, t), nil
+	}
+
+	return nil, errUnsupportedFeature("ALTER TABLE " + op)
+}
+
+// convertCreateIndex converts the submatches of createIndex: the kind of
+// index, its name, its type before and after the table, the table and the
+// columns.
+func convertCreateIndex(m []string) (sql.Node, error) {
+	if kind := strings.TrimSpace(m[1]); kind != "" {
+		return nil, errUnsupportedFeature("CREATE " + strings.ToUpper(kind) + " INDEX")
+	}
+
+	columns := strings.Split(m[5], ",")
+	if len(columns) != 1 {
+		return nil, errUnsupportedFeature("multi-column indexes")
+	}
+
+	typ := m[3]
+	if typ == "" {
+		typ = m[6]
+	}
+
+	index := sql.Index{
+		Name:   unquote(m[2]),
+		Column: unquote(strings.TrimSpace(columns[0])),
+		Type:   sql.BTreeIndex,
+	}
+
+	switch strings.ToLower(typ) {
+	case "", "btree":
+	case "hash":
+		index.Type = sql.HashIndex
+	default:
+		return nil, errUnsupportedFeature("index type " + typ)
+	}
+
+	return plan.NewCreateIndex(index, plan.NewUnresolvedTable(unquote(m[4]))), nil
+}
+
+func unquote(name string) string {
+	return strings.Trim(name, "`")
+}
+
+func convertCreateTable(d *sqlparser.DDL) (sql.Node, error) {
+	if d.TableSpec == nil {
+		return nil, errUnsupported(d)
+	}
+
+	schema := make(sql.Schema, len(d.TableSpec.Columns))
+	for i, cd := range d.TableSpec.Columns {
+		col, err := columnDefinitionToColumn(cd)
+		if err != nil {
+			return nil, err
+		}
+
+		schema[i] = col
+	}
+
+	for _, idx := range d.TableSpec.Indexes {
+		if err := indexDefinitionToKey(schema, idx); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan.NewCreateTable(
+		&sql.UnresolvedDatabase{},
+		d.NewName.Name.String(),
+		schema,
+		false,
 	), nil
 }
 
+func columnDefinitionToColumn(cd *sqlparser.ColumnDefinition) (*sql.Column, error) {
+	typ, err := columnTypeToType(cd.Type.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	col := &sql.Column{
+		Name:     cd.Name.String(),
+		Type:     typ,
+		Nullable: !bool(cd.Type.NotNull),
+	}
+
+	// No table generates values for keys yet.
+	if cd.Type.Autoincrement {
+		return nil, errUnsupportedFeature("AUTO_INCREMENT")
+	}
+
+	switch columnKeyOption(cd.Type) {
+	case "":
+	case "primary key", "key":
+		col.PrimaryKey = true
+		col.Nullable = false
+	case "unique", "unique key":
+		col.Unique = true
+	default:
+		return nil, errUnsupportedFeature(strings.ToUpper(columnKeyOption(cd.Type)))
+	}
+
+	if cd.Type.Default == nil {
+		return col, nil
+	}
+
+	// DEFAULT NULL is parsed as an argument named null.
+	if cd.Type.Default.Type == sqlparser.ValArg {
+		if strings.ToLower(string(cd.Type.Default.Val)) != "null" {
+			return nil, errUnsupportedFeature("DEFAULT " + string(cd.Type.Default.Val))
+		}
+
+		return col, nil
+	}
+
+	e, err := exprToExpression(cd.Type.Default)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := e.Eval(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	col.Default, err = typ.Convert(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default value for column %s: %s", col.Name, err)
+	}
+
+	return col, nil
+}
+
+// columnKeyOption returns the key option of a column definition in lower
+// case, such as "primary key" or "unique", or empty if it has none. The
+// option values are not exported by the parser, so they are formatted.
+func columnKeyOption(ct sqlparser.ColumnType) string {
+	opt := &sqlparser.ColumnType{KeyOpt: ct.KeyOpt}
+	return strings.ToLower(strings.TrimSpace(sqlparser.String(opt)))
+}
+
+// indexDefinitionToKey marks the columns of a PRIMARY KEY or single column
+// UNIQUE definition in the schema.
+func indexDefinitionToKey(schema sql.Schema, idx *sqlparser.IndexDefinition) error {
+	if !idx.Info.Primary && !(idx.Info.Unique && len(idx.Columns) == 1) {
+		return errUnsupportedFeature("indexes in CREATE TABLE")
+	}
+
+	for _, ic := range idx.Columns {
+		col := columnByName(schema, ic.Column.String())
+		if col == nil {
+			return fmt.Errorf("key column %s doesn't exist in table", ic.Column)
+		}
+
+		if idx.Info.Primary {
+			col.PrimaryKey = true
+			col.Nullable = false
+		} else {
+			col.Unique = true
+		}
+	}
+
+	return nil
+}
+
+func columnByName(schema sql.Schema, name string) *sql.Column {
+	for _, c := range schema {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// columnTypeToType returns the sql.Type used to store values of the SQL type
+// with the given name.
+func columnTypeToType(name string) (sql.Type, error) {
+	switch strings.ToLower(name) {
+	case "tinyint", "smallint", "mediumint", "int", "integer":
+		return sql.Integer, nil
+	case "bigint":
+		return sql.BigInteger, nil
+	case "float", "double", "real", "decimal", "numeric":
+		return sql.Float, nil
+	case "bool", "boolean":
+		return sql.Boolean, nil
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
+		return sql.String, nil
+	case "date", "datetime", "timestamp":
+		return sql.TimestampWithTimezone, nil
+	default:
+		return nil, errUnsupportedFeature("column type " + name)
+	}
+}
+
+// modifiedRowsToNode returns the node with the rows of the table modified by
+// an UPDATE or a DELETE.
+func modifiedRowsToNode(
+	w *sqlparser.Where,
+	ob sqlparser.OrderBy,
+	l *sqlparser.Limit,
+	table sql.Node,
+) (sql.Node, error) {
+	var err error
+	node := table
+	if w != nil {
+		node, err = whereToFilter(w, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ob) != 0 {
+		node, err = orderByToSort(ob, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if l != nil {
+		if l.Offset != nil {
+			return nil, errUnsupportedFeature("OFFSET in UPDATE or DELETE")
+		}
+
+		node, err = limitToLimit(l.Rowcount, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return node, nil
+}
+
 func columnsToStrings(cols sqlparser.Columns) []string {
 	res := make([]string, len(cols))
 	for i, c := range cols {
@@ -181,16 +595,12 @@ func tableExprsToTable(te sqlparser.TableExprs) (sql.Node, error) {
 		nodes = append(nodes, n)
 	}
 
-	if len(nodes) == 1 {
-		return nodes[0], nil
+	node := nodes[0]
+	for _, n := range nodes[1:] {
+		node = plan.NewCrossJoin(node, n)
 	}
 
-	if len(nodes) == 2 {
-		return plan.NewCrossJoin(nodes[0], nodes[1]), nil
-	}
-
-	//TODO: Support N tables in JOIN.
-	return nil, errUnsupportedFeature("more than 2 tables in JOIN")
+	return node, nil
 }
 
 func tableExprToTable(te sqlparser.TableExpr) (sql.Node, error) {
@@ -198,14 +608,74 @@ func tableExprToTable(te sqlparser.TableExpr) (sql.Node, error) {
 	default:
 		return nil, errUnsupported(te)
 	case *sqlparser.AliasedTableExpr:
-		//TODO: Add support for table alias.
-		//TODO: Add support for qualifier.
 		tn, ok := t.Expr.(*sqlparser.TableName)
 		if !ok {
 			return nil, errUnsupportedFeature("non simple tables")
 		}
 
-		return plan.NewUnresolvedTable(tn.Name.String()), nil
+		var node sql.Node = plan.NewUnresolvedQualifiedTable(
+			tn.Qualifier.String(), tn.Name.String())
+		if !t.As.IsEmpty() {
+			node = plan.NewTableAlias(t.As.String(), node)
+		}
+
+		return node, nil
+	case *sqlparser.ParenTableExpr:
+		return tableExprsToTable(t.Exprs)
+	case *sqlparser.JoinTableExpr:
+		return joinTableExprToJoin(t)
+	}
+}
+
+func joinTableExprToJoin(j *sqlparser.JoinTableExpr) (sql.Node, error) {
+	left, err := tableExprToTable(j.LeftExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := tableExprToTable(j.RightExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	if j.Condition.On == nil && len(j.Condition.Using) == 0 {
+		if j.Join != sqlparser.JoinStr {
+			return nil, errUnsupportedFeature(j.Join + " without condition")
+		}
+
+		return plan.NewCrossJoin(left, right), nil
+	}
+
+	if len(j.Condition.Using) > 0 {
+		using := columnsToStrings(j.Condition.Using)
+		switch j.Join {
+		case sqlparser.JoinStr:
+			return plan.NewInnerJoinUsing(left, right, using), nil
+		case sqlparser.LeftJoinStr:
+			return plan.NewLeftJoinUsing(left, right, using), nil
+		case sqlparser.RightJoinStr:
+			return plan.NewRightJoinUsing(left, right, using), nil
+		default:
+			return nil, errUnsupportedFeature(j.Join)
+		}
+	}
+
+	cond, err := exprToExpression(j.Condition.On)
+	if err != nil {
+		return nil, err
+	}
+
+	// The grammar has no FULL OUTER JOIN, so plan.FullOuterJoin is never
+	// built by the parser.
+	switch j.Join {
+	case sqlparser.JoinStr:
+		return plan.NewInnerJoin(left, right, cond), nil
+	case sqlparser.LeftJoinStr:
+		return plan.NewLeftJoin(left, right, cond), nil
+	case sqlparser.RightJoinStr:
+		return plan.NewRightJoin(left, right, cond), nil
+	default:
+		return nil, errUnsupportedFeature(j.Join)
 	}
 }
 
@@ -249,15 +719,61 @@ func limitToLimit(o sqlparser.Expr, child sql.Node) (*plan.Limit, error) {
 		return nil, err
 	}
 
-	nl, ok := e.(*expression.Literal)
-	if !ok || nl.Type() != sql.BigInteger {
-		return nil, errUnsupportedFeature("LIMIT with non-integer literal")
+	if _, ok := e.(*expression.Placeholder); ok {
+		return plan.NewLimitExpression(e, child), nil
+	}
+
+	n, err := evalConstantInteger(e)
+	if err != nil {
+		return nil, err
 	}
 
-	n := (nl.Eval(nil)).(int64)
 	return plan.NewLimit(n, child), nil
 }
 
+func offsetToOffset(o sqlparser.Expr, child sql.Node) (*plan.Offset, error) {
+	e, err := exprToExpression(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := e.(*expression.Placeholder); ok {
+		return plan.NewOffsetExpression(e, child), nil
+	}
+
+	n, err := evalConstantInteger(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan.NewOffset(n, child), nil
+}
+
+// evalConstantInteger evaluates an expression made only of constants, such as
+// an integer literal or an arithmetic expression between them.
+func evalConstantInteger(e sql.Expression) (int64, error) {
+	if !e.Resolved() ||
+		(e.Type() != sql.BigInteger && e.Type() != sql.Integer) {
+		return 0, errUnsupportedFeature("LIMIT or OFFSET with non-integer value")
+	}
+
+	v, err := e.Eval(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := sql.BigInteger.Convert(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if n.(int64) < 0 {
+		return 0, fmt.Errorf("LIMIT and OFFSET can't be negative: %d", n)
+	}
+
+	return n.(int64), nil
+}
+
 func isAggregate(e sql.Expression) bool {
 	switch v := e.(type) {
 	case *expression.UnresolvedFunction:
@@ -326,6 +842,26 @@ func exprToExpression(e sqlparser.Expr) (sql.Expression, error) {
 		}
 
 		return expression.NewNot(c), nil
+	case *sqlparser.AndExpr:
+		left, right, err := binaryExprsToExpressions(v.Left, v.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		return expression.NewAnd(left, right), nil
+	case *sqlparser.OrExpr:
+		left, right, err := binaryExprsToExpressions(v.Left, v.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		return expression.NewOr(left, right), nil
+	case *sqlparser.ParenExpr:
+		return exprToExpression(v.Expr)
+	case *sqlparser.BinaryExpr:
+		return binaryExprToExpression(v)
+	case *sqlparser.UnaryExpr:
+		return unaryExprToExpression(v)
 	case *sqlparser.SQLVal:
 		switch v.Type {
 		case sqlparser.StrVal:
@@ -334,9 +870,21 @@ func exprToExpression(e sqlparser.Expr) (sql.Expression, error) {
 			//TODO: Use smallest integer representation and widen later.
 			n, _ := strconv.ParseInt(string(v.Val), 10, 64)
 			return expression.NewLiteral(n, sql.BigInteger), nil
+		case sqlparser.FloatVal:
+			n, err := strconv.ParseFloat(string(v.Val), 64)
+			if err != nil {
+				return nil, err
+			}
+
+			return expression.NewLiteral(n, sql.Float), nil
 		case sqlparser.HexVal:
 			//TODO
 			return nil, errUnsupported(v)
+		case sqlparser.ValArg:
+			// Positional placeholders are numbered by the parser as :v1,
+			// :v2, ... so both kinds are handled the same way.
+			name := strings.TrimPrefix(string(v.Val), ":")
+			return expression.NewPlaceholder(name), nil
 		default:
 			//TODO
 			return nil, errUnsupported(v)
@@ -345,8 +893,17 @@ func exprToExpression(e sqlparser.Expr) (sql.Expression, error) {
 		return expression.NewLiteral(bool(v), sql.Boolean), nil
 	case *sqlparser.NullVal:
 		return expression.NewLiteral(nil, sql.Null), nil
+	case *sqlparser.ValuesFuncExpr:
+		return expression.NewUnresolvedValuesColumn(v.Name.Name.String()), nil
 	case *sqlparser.ColName:
 		//TODO: add handling of case sensitiveness.
+		// Columns qualified with a database are only matched by table, as
+		// the source of the columns does not include it.
+		if !v.Qualifier.IsEmpty() {
+			return expression.NewUnresolvedQualifiedColumn(
+				v.Qualifier.Name.String(), v.Name.Lowered()), nil
+		}
+
 		return expression.NewUnresolvedColumn(v.Name.Lowered()), nil
 	case *sqlparser.FuncExpr:
 		exprs, err := selectExprsToExpressions(v.Exprs)
@@ -354,11 +911,72 @@ func exprToExpression(e sqlparser.Expr) (sql.Expression, error) {
 			return nil, err
 		}
 
+		if v.Distinct {
+			return expression.NewUnresolvedDistinctFunction(v.Name.Lowered(),
+				exprs...), nil
+		}
+
 		return expression.NewUnresolvedFunction(v.Name.Lowered(),
 			v.IsAggregate(), exprs...), nil
 	}
 }
 
+func binaryExprsToExpressions(l, r sqlparser.Expr) (sql.Expression,
+	sql.Expression, error) {
+
+	left, err := exprToExpression(l)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	right, err := exprToExpression(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return left, right, nil
+}
+
+func binaryExprToExpression(be *sqlparser.BinaryExpr) (sql.Expression, error) {
+	left, right, err := binaryExprsToExpressions(be.Left, be.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch be.Operator {
+	default:
+		return nil, errUnsupportedFeature(be.Operator)
+	case sqlparser.PlusStr:
+		return expression.NewPlus(left, right), nil
+	case sqlparser.MinusStr:
+		return expression.NewMinus(left, right), nil
+	case sqlparser.MultStr:
+		return expression.NewMult(left, right), nil
+	case sqlparser.DivStr:
+		return expression.NewDiv(left, right), nil
+	case sqlparser.IntDivStr:
+		return expression.NewIntDiv(left, right), nil
+	case sqlparser.ModStr:
+		return expression.NewMod(left, right), nil
+	}
+}
+
+func unaryExprToExpression(ue *sqlparser.UnaryExpr) (sql.Expression, error) {
+	e, err := exprToExpression(ue.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ue.Operator {
+	default:
+		return nil, errUnsupportedFeature(ue.Operator)
+	case sqlparser.UPlusStr:
+		return e, nil
+	case sqlparser.UMinusStr:
+		return expression.NewUnaryMinus(e), nil
+	}
+}
+
 func isExprToExpression(c *sqlparser.IsExpr) (sql.Expression, error) {
 	e, err := exprToExpression(c.Expr)
 	if err != nil {
@@ -429,7 +1047,10 @@ func selectExprToExpression(se sqlparser.SelectExpr) (sql.Expression, error) {
 	default:
 		return nil, errUnsupported(e)
 	case *sqlparser.StarExpr:
-		//TODO: Add support for qualified start.
+		if !e.TableName.IsEmpty() {
+			return expression.NewQualifiedStar(e.TableName.Name.String()), nil
+		}
+
 		return expression.NewStar(), nil
 	case *sqlparser.NonStarExpr:
 		expr, err := exprToExpression(e.Expr)
@@ -149,6 +149,45 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("t2"),
 		),
 	),
+	`SELECT foo, bar, baz FROM t1, t2, t3;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+			expression.NewUnresolvedColumn("bar"),
+			expression.NewUnresolvedColumn("baz"),
+		},
+		plan.NewCrossJoin(
+			plan.NewCrossJoin(
+				plan.NewUnresolvedTable("t1"),
+				plan.NewUnresolvedTable("t2"),
+			),
+			plan.NewUnresolvedTable("t3"),
+		),
+	),
+	`SELECT a.foo, b.bar, t1.baz FROM t1 AS a, mydb.t2 b;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedQualifiedColumn("a", "foo"),
+			expression.NewUnresolvedQualifiedColumn("b", "bar"),
+			expression.NewUnresolvedQualifiedColumn("t1", "baz"),
+		},
+		plan.NewCrossJoin(
+			plan.NewTableAlias("a", plan.NewUnresolvedTable("t1")),
+			plan.NewTableAlias("b", plan.NewUnresolvedQualifiedTable("mydb", "t2")),
+		),
+	),
+	`SELECT mydb.t1.foo FROM mydb.t1;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedQualifiedColumn("t1", "foo"),
+		},
+		plan.NewUnresolvedQualifiedTable("mydb", "t1"),
+	),
+	`SELECT t1.*, *, bar FROM t1;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewQualifiedStar("t1"),
+			expression.NewStar(),
+			expression.NewUnresolvedColumn("bar"),
+		},
+		plan.NewUnresolvedTable("t1"),
+	),
 	`SELECT foo, bar FROM t1 GROUP BY foo, bar;`: plan.NewGroupBy(
 		[]sql.Expression{
 			expression.NewUnresolvedColumn("foo"),
@@ -168,6 +207,97 @@ var fixtures = map[string]sql.Node{
 		[]sql.Expression{},
 		plan.NewUnresolvedTable("t1"),
 	),
+	`SELECT DISTINCT foo, bar FROM t1;`: plan.NewDistinct(
+		plan.NewProject(
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("foo"),
+				expression.NewUnresolvedColumn("bar"),
+			},
+			plan.NewUnresolvedTable("t1"),
+		),
+	),
+	`SELECT foo, COUNT(DISTINCT bar) FROM t1 GROUP BY foo;`: plan.NewGroupBy(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+			expression.NewUnresolvedDistinctFunction("count",
+				expression.NewUnresolvedColumn("bar")),
+		},
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+		},
+		plan.NewUnresolvedTable("t1"),
+	),
+	`SELECT foo, COUNT(*) AS c FROM t1 GROUP BY foo HAVING c > 1 AND MAX(bar) < 5;`: plan.NewHaving(
+		expression.NewAnd(
+			expression.NewGreaterThan(
+				expression.NewUnresolvedColumn("c"),
+				expression.NewLiteral(int64(1), sql.BigInteger),
+			),
+			expression.NewLessThan(
+				expression.NewUnresolvedFunction("max", true,
+					expression.NewUnresolvedColumn("bar")),
+				expression.NewLiteral(int64(5), sql.BigInteger),
+			),
+		),
+		plan.NewGroupBy(
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("foo"),
+				expression.NewAlias(
+					expression.NewUnresolvedFunction("count", true,
+						expression.NewStar()),
+					"c",
+				),
+			},
+			[]sql.Expression{
+				expression.NewUnresolvedColumn("foo"),
+			},
+			plan.NewUnresolvedTable("t1"),
+		),
+	),
+	`SELECT foo FROM t1 LIMIT 10 OFFSET 5;`: plan.NewLimit(10,
+		plan.NewOffset(5,
+			plan.NewProject(
+				[]sql.Expression{
+					expression.NewUnresolvedColumn("foo"),
+				},
+				plan.NewUnresolvedTable("t1"),
+			),
+		),
+	),
+	`SELECT foo FROM t1 LIMIT 5, 10;`: plan.NewLimit(10,
+		plan.NewOffset(5,
+			plan.NewProject(
+				[]sql.Expression{
+					expression.NewUnresolvedColumn("foo"),
+				},
+				plan.NewUnresolvedTable("t1"),
+			),
+		),
+	),
+	`SELECT foo FROM t1 WHERE a = ? AND b = :name LIMIT ?, ?;`: plan.NewLimitExpression(
+		expression.NewPlaceholder("v3"),
+		plan.NewOffsetExpression(
+			expression.NewPlaceholder("v2"),
+			plan.NewProject(
+				[]sql.Expression{
+					expression.NewUnresolvedColumn("foo"),
+				},
+				plan.NewFilter(
+					expression.NewAnd(
+						expression.NewEquals(
+							expression.NewUnresolvedColumn("a"),
+							expression.NewPlaceholder("v1"),
+						),
+						expression.NewEquals(
+							expression.NewUnresolvedColumn("b"),
+							expression.NewPlaceholder("name"),
+						),
+					),
+					plan.NewUnresolvedTable("t1"),
+				),
+			),
+		),
+	),
 	`SELECT a FROM t1 where a regexp '.*test.*';`: plan.NewProject(
 		[]sql.Expression{
 			expression.NewUnresolvedColumn("a"),
@@ -180,6 +310,76 @@ var fixtures = map[string]sql.Node{
 			plan.NewUnresolvedTable("t1"),
 		),
 	),
+	`SELECT a FROM t1 WHERE a = 1 AND (b = 2 OR NOT c);`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("a"),
+		},
+		plan.NewFilter(
+			expression.NewAnd(
+				expression.NewEquals(
+					expression.NewUnresolvedColumn("a"),
+					expression.NewLiteral(int64(1), sql.BigInteger),
+				),
+				expression.NewOr(
+					expression.NewEquals(
+						expression.NewUnresolvedColumn("b"),
+						expression.NewLiteral(int64(2), sql.BigInteger),
+					),
+					expression.NewNot(expression.NewUnresolvedColumn("c")),
+				),
+			),
+			plan.NewUnresolvedTable("t1"),
+		),
+	),
+	`SELECT a * 2, -b FROM t1 LIMIT 2 + 3;`: plan.NewLimit(int64(5),
+		plan.NewProject(
+			[]sql.Expression{
+				expression.NewMult(
+					expression.NewUnresolvedColumn("a"),
+					expression.NewLiteral(int64(2), sql.BigInteger),
+				),
+				expression.NewUnaryMinus(expression.NewUnresolvedColumn("b")),
+			},
+			plan.NewUnresolvedTable("t1"),
+		),
+	),
+	`SELECT foo, bar FROM t1 JOIN t2 ON foo = bar;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+			expression.NewUnresolvedColumn("bar"),
+		},
+		plan.NewInnerJoin(
+			plan.NewUnresolvedTable("t1"),
+			plan.NewUnresolvedTable("t2"),
+			expression.NewEquals(
+				expression.NewUnresolvedColumn("foo"),
+				expression.NewUnresolvedColumn("bar"),
+			),
+		),
+	),
+	`SELECT foo FROM t1 LEFT JOIN t2 USING (foo);`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+		},
+		plan.NewLeftJoinUsing(
+			plan.NewUnresolvedTable("t1"),
+			plan.NewUnresolvedTable("t2"),
+			[]string{"foo"},
+		),
+	),
+	`SELECT foo FROM t1 RIGHT OUTER JOIN t2 ON foo = bar;`: plan.NewProject(
+		[]sql.Expression{
+			expression.NewUnresolvedColumn("foo"),
+		},
+		plan.NewRightJoin(
+			plan.NewUnresolvedTable("t1"),
+			plan.NewUnresolvedTable("t2"),
+			expression.NewEquals(
+				expression.NewUnresolvedColumn("foo"),
+				expression.NewUnresolvedColumn("bar"),
+			),
+		),
+	),
 	`INSERT INTO t1 (col1, col2) VALUES ('a', 1)`: plan.NewInsertInto(
 		plan.NewUnresolvedTable("t1"),
 		plan.NewValues([][]sql.Expression{{
@@ -188,6 +388,136 @@ var fixtures = map[string]sql.Node{
 		}}),
 		[]string{"col1", "col2"},
 	),
+	`DELETE FROM t1 WHERE a = 1 LIMIT 10`: plan.NewDelete(
+		plan.NewLimit(10,
+			plan.NewFilter(
+				expression.NewEquals(
+					expression.NewUnresolvedColumn("a"),
+					expression.NewLiteral(int64(1), sql.BigInteger),
+				),
+				plan.NewUnresolvedTable("t1"),
+			),
+		),
+	),
+	`TRUNCATE TABLE t1`: plan.NewDelete(plan.NewUnresolvedTable("t1")),
+	`CREATE TABLE t1 (a INT NOT NULL, b VARCHAR(10) DEFAULT 'x', c BIGINT DEFAULT 1, d TIMESTAMP, e DOUBLE DEFAULT NULL)`: plan.NewCreateTable(
+		&sql.UnresolvedDatabase{},
+		"t1",
+		sql.Schema{
+			{Name: "a", Type: sql.Integer, Nullable: false},
+			{Name: "b", Type: sql.String, Default: "x", Nullable: true},
+			{Name: "c", Type: sql.BigInteger, Default: int64(1), Nullable: true},
+			{Name: "d", Type: sql.TimestampWithTimezone, Nullable: true},
+			{Name: "e", Type: sql.Float, Nullable: true},
+		},
+		false,
+	),
+	`CREATE TABLE IF NOT EXISTS t1 (a TEXT)`: plan.NewCreateTable(
+		&sql.UnresolvedDatabase{},
+		"t1",
+		sql.Schema{{Name: "a", Type: sql.String, Nullable: true}},
+		true,
+	),
+	`CREATE TABLE t1 (a INT PRIMARY KEY, b TEXT UNIQUE, c TEXT)`: plan.NewCreateTable(
+		&sql.UnresolvedDatabase{},
+		"t1",
+		sql.Schema{
+			{Name: "a", Type: sql.Integer, PrimaryKey: true},
+			{Name: "b", Type: sql.String, Nullable: true, Unique: true},
+			{Name: "c", Type: sql.String, Nullable: true},
+		},
+		false,
+	),
+	`CREATE TABLE t1 (a INT, b TEXT, c TEXT, PRIMARY KEY (a, b), UNIQUE KEY c_idx (c))`: plan.NewCreateTable(
+		&sql.UnresolvedDatabase{},
+		"t1",
+		sql.Schema{
+			{Name: "a", Type: sql.Integer, PrimaryKey: true},
+			{Name: "b", Type: sql.String, PrimaryKey: true},
+			{Name: "c", Type: sql.String, Nullable: true, Unique: true},
+		},
+		false,
+	),
+	`CREATE INDEX idx ON t1 (a)`: plan.NewCreateIndex(
+		sql.Index{Name: "idx", Column: "a", Type: sql.BTreeIndex},
+		plan.NewUnresolvedTable("t1"),
+	),
+	"CREATE INDEX `idx` USING HASH ON `t1`(`a`)": plan.NewCreateIndex(
+		sql.Index{Name: "idx", Column: "a", Type: sql.HashIndex},
+		plan.NewUnresolvedTable("t1"),
+	),
+	`create index idx on t1 ( a ) using btree;`: plan.NewCreateIndex(
+		sql.Index{Name: "idx", Column: "a", Type: sql.BTreeIndex},
+		plan.NewUnresolvedTable("t1"),
+	),
+	`ALTER TABLE t1 ADD COLUMN c INT NOT NULL DEFAULT 5`: plan.NewAddColumn(
+		&sql.Column{Name: "c", Type: sql.Integer, Default: int32(5)},
+		plan.NewUnresolvedTable("t1"),
+	),
+	"ALTER TABLE `t1` ADD c TEXT": plan.NewAddColumn(
+		&sql.Column{Name: "c", Type: sql.String, Nullable: true},
+		plan.NewUnresolvedTable("t1"),
+	),
+	`alter table t1 drop column c`: plan.NewDropColumn("c", plan.NewUnresolvedTable("t1")),
+	`ALTER TABLE t1 DROP c`:        plan.NewDropColumn("c", plan.NewUnresolvedTable("t1")),
+	`ALTER TABLE t1 RENAME COLUMN a TO b`: plan.NewRenameColumn("a", "b",
+		plan.NewUnresolvedTable("t1"),
+	),
+	`DROP TABLE t1`:           plan.NewDropTable(&sql.UnresolvedDatabase{}, "t1", false),
+	`DROP TABLE IF EXISTS t1`: plan.NewDropTable(&sql.UnresolvedDatabase{}, "t1", true),
+	`INSERT IGNORE INTO t1 (col1) VALUES ('a')`: plan.NewInsertIgnore(
+		plan.NewUnresolvedTable("t1"),
+		plan.NewValues([][]sql.Expression{{
+			expression.NewLiteral("a", sql.String),
+		}}),
+		[]string{"col1"},
+	),
+	`REPLACE INTO t1 (col1) VALUES ('a')`: plan.NewReplaceInto(
+		plan.NewUnresolvedTable("t1"),
+		plan.NewValues([][]sql.Expression{{
+			expression.NewLiteral("a", sql.String),
+		}}),
+		[]string{"col1"},
+	),
+	`INSERT INTO t1 (col1, col2) VALUES ('a', 1) ON DUPLICATE KEY UPDATE col2 = col2 + VALUES(col2)`: plan.NewInsertOnDuplicateKeyUpdate(
+		plan.NewUnresolvedTable("t1"),
+		plan.NewValues([][]sql.Expression{{
+			expression.NewLiteral("a", sql.String),
+			expression.NewLiteral(int64(1), sql.BigInteger),
+		}}),
+		[]string{"col1", "col2"},
+		[]plan.UpdateField{{
+			Column: expression.NewUnresolvedColumn("col2"),
+			Value: expression.NewPlus(
+				expression.NewUnresolvedColumn("col2"),
+				expression.NewUnresolvedValuesColumn("col2"),
+			),
+		}},
+	),
+	`UPDATE t1 SET a = a + 1, b = 'x' WHERE c = 2 LIMIT 1`: plan.NewUpdate(
+		[]plan.UpdateField{
+			{
+				Column: expression.NewUnresolvedColumn("a"),
+				Value: expression.NewPlus(
+					expression.NewUnresolvedColumn("a"),
+					expression.NewLiteral(int64(1), sql.BigInteger),
+				),
+			},
+			{
+				Column: expression.NewUnresolvedColumn("b"),
+				Value:  expression.NewLiteral("x", sql.String),
+			},
+		},
+		plan.NewLimit(1,
+			plan.NewFilter(
+				expression.NewEquals(
+					expression.NewUnresolvedColumn("c"),
+					expression.NewLiteral(int64(2), sql.BigInteger),
+				),
+				plan.NewUnresolvedTable("t1"),
+			),
+		),
+	),
 }
 
 func TestParse(t *testing.T) {
@@ -202,3 +532,39 @@ func TestParse(t *testing.T) {
 
 	}
 }
+
+func TestParse_InvalidCreateTable(t *testing.T) {
+	for _, query := range []string{
+		`CREATE TABLE t1 (a BLOB)`,
+		`CREATE TABLE t1 (a INT DEFAULT 'a')`,
+		`CREATE TABLE t1 (a TIMESTAMP DEFAULT CURRENT_TIMESTAMP)`,
+		`CREATE TABLE t1 (a INT, b INT, UNIQUE KEY (a, b))`,
+		`CREATE TABLE t1 (a INT, KEY idx (a))`,
+		`CREATE TABLE t1 (a INT, PRIMARY KEY (b))`,
+		`CREATE TABLE t1 (a INT NOT NULL AUTO_INCREMENT PRIMARY KEY)`,
+		`CREATE UNIQUE INDEX idx ON t1 (a)`,
+		`CREATE INDEX idx ON t1 (a, b)`,
+		`CREATE INDEX idx USING RTREE ON t1 (a)`,
+		`ALTER TABLE t1 ADD COLUMN a BLOB`,
+		`ALTER TABLE t1 ADD INDEX idx (a)`,
+		`ALTER TABLE t1 ENGINE = InnoDB`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := Parse(query)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParse_InvalidLimit(t *testing.T) {
+	for _, query := range []string{
+		`SELECT foo FROM t1 LIMIT 'a';`,
+		`SELECT foo FROM t1 LIMIT 1 OFFSET 1.5;`,
+		`SELECT foo FROM t1 LIMIT 1 - 2;`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := Parse(query)
+			assert.Error(t, err)
+		})
+	}
+}
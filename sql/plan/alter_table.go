@@ -0,0 +1,151 @@
+package plan
+
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// AddColumn appends a column to its child, a table implementing
+// sql.ColumnAlterer.
+type AddColumn struct {
+	UnaryNode
+	Column *sql.Column
+}
+
+// NewAddColumn creates a new AddColumn node.
+func NewAddColumn(column *sql.Column, table sql.Node) *AddColumn {
+	return &AddColumn{UnaryNode{table}, column}
+}
+
+func (*AddColumn) Schema() sql.Schema {
+	return sql.Schema{}
+}
+
+// Execute adds the column to the table.
+func (p *AddColumn) Execute() (sql.Result, error) {
+	t, err := columnAlterer(p.Child)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	return sql.Result{}, t.AddColumn(p.Column)
+}
+
+func (p *AddColumn) RowIter() (sql.RowIter, error) {
+	return executeToRowIter(p)
+}
+
+func (p *AddColumn) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewAddColumn(p.Column, c))
+}
+
+func (p *AddColumn) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewAddColumn(p.Column, c)
+}
+
+// DropColumn removes a column from its child, a table implementing
+// sql.ColumnAlterer.
+type DropColumn struct {
+	UnaryNode
+	Name string
+}
+
+// NewDropColumn creates a new DropColumn node.
+func NewDropColumn(name string, table sql.Node) *DropColumn {
+	return &DropColumn{UnaryNode{table}, name}
+}
+
+func (*DropColumn) Schema() sql.Schema {
+	return sql.Schema{}
+}
+
+// Execute removes the column from the table.
+func (p *DropColumn) Execute() (sql.Result, error) {
+	t, err := columnAlterer(p.Child)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	return sql.Result{}, t.DropColumn(p.Name)
+}
+
+func (p *DropColumn) RowIter() (sql.RowIter, error) {
+	return executeToRowIter(p)
+}
+
+func (p *DropColumn) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewDropColumn(p.Name, c))
+}
+
+func (p *DropColumn) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewDropColumn(p.Name, c)
+}
+
+// RenameColumn changes the name of a column of its child, a table
+// implementing sql.ColumnAlterer.
+type RenameColumn struct {
+	UnaryNode
+	Name    string
+	NewName string
+}
+
+// NewRenameColumn creates a new RenameColumn node.
+func NewRenameColumn(name, newName string, table sql.Node) *RenameColumn {
+	return &RenameColumn{UnaryNode{table}, name, newName}
+}
+
+func (*RenameColumn) Schema() sql.Schema {
+	return sql.Schema{}
+}
+
+// Execute renames the column of the table.
+func (p *RenameColumn) Execute() (sql.Result, error) {
+	t, err := columnAlterer(p.Child)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	return sql.Result{}, t.RenameColumn(p.Name, p.NewName)
+}
+
+func (p *RenameColumn) RowIter() (sql.RowIter, error) {
+	return executeToRowIter(p)
+}
+
+func (p *RenameColumn) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewRenameColumn(p.Name, p.NewName, c))
+}
+
+func (p *RenameColumn) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewRenameColumn(p.Name, p.NewName, c)
+}
+
+func columnAlterer(n sql.Node) (sql.ColumnAlterer, error) {
+	t, err := findTable(n)
+	if err != nil {
+		return nil, err
+	}
+
+	a, ok := t.(sql.ColumnAlterer)
+	if !ok {
+		return nil, fmt.Errorf("columns of table %s can't be altered", t.Name())
+	}
+
+	return a, nil
+}
+
+// executeToRowIter executes the node and returns an iterator without rows.
+func executeToRowIter(e sql.Executor) (sql.RowIter, error) {
+	if _, err := e.Execute(); err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(), nil
+}
@@ -0,0 +1,59 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlterTable(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+	})
+	require.NoError(table.Insert(sql.NewRow(int64(1))))
+
+	_, err := NewAddColumn(
+		&sql.Column{Name: "b", Type: sql.String, Default: "x"},
+		NewTableAlias("alias", table),
+	).Execute()
+	require.NoError(err)
+
+	rows, err := sql.NodeToRows(NewRenameColumn("a", "c", table))
+	require.NoError(err)
+	require.Len(rows, 0)
+
+	require.Equal("c", table.Schema()[0].Name)
+	require.Equal("b", table.Schema()[1].Name)
+
+	rows, err = sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(1), "x")}, rows)
+
+	_, err = NewDropColumn("c", table).Execute()
+	require.NoError(err)
+
+	rows, err = sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow("x")}, rows)
+
+	_, err = NewDropColumn("c", table).Execute()
+	require.Error(err)
+}
+
+func TestAlterTable_NotAlterable(t *testing.T) {
+	require := require.New(t)
+
+	_, err := NewDropColumn("a", NewShowTables(nil)).Execute()
+	require.Error(err)
+
+	_, err = NewAddColumn(&sql.Column{Name: "a"}, NewShowTables(nil)).Execute()
+	require.Error(err)
+
+	_, err = NewRenameColumn("a", "b", NewShowTables(nil)).Execute()
+	require.Error(err)
+}
@@ -1,6 +1,10 @@
 package plan
 
-import "gopkg.in/sqle/sqle.v0/sql"
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
 
 type UnaryNode struct {
 	Child sql.Node
@@ -52,3 +56,23 @@ func transformExpressionsUp(f func(sql.Expression) sql.Expression,
 
 	return es
 }
+
+// findTable returns the table the rows of the node are read from, going down
+// through nodes with a single child, such as filters or aliases.
+func findTable(n sql.Node) (sql.Table, error) {
+	for {
+		// Aliases are named nodes too, but not the table itself.
+		if _, ok := n.(*TableAlias); !ok {
+			if t, ok := n.(sql.Table); ok {
+				return t, nil
+			}
+		}
+
+		children := n.Children()
+		if len(children) != 1 {
+			return nil, fmt.Errorf("can't find the table to modify")
+		}
+
+		n = children[0]
+	}
+}
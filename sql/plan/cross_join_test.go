@@ -14,7 +14,7 @@ var lSchema = sql.Schema{
 	{Name: "lcol1", Type: sql.String},
 	{Name: "lcol2", Type: sql.String},
 	{Name: "lcol3", Type: sql.Integer},
-	{Name: "lcol4", Type: sql.BigInteger},
+	{Name: "lcol4", Type: sql.BigInteger, Source: "left"},
 }
 
 var rSchema = sql.Schema{
@@ -28,14 +28,14 @@ func TestCrossJoin(t *testing.T) {
 	assert := assert.New(t)
 
 	resultSchema := sql.Schema{
-		{Name: "lcol1", Type: sql.String},
-		{Name: "lcol2", Type: sql.String},
-		{Name: "lcol3", Type: sql.Integer},
-		{Name: "lcol4", Type: sql.BigInteger},
-		{Name: "rcol1", Type: sql.String},
-		{Name: "rcol2", Type: sql.String},
-		{Name: "rcol3", Type: sql.Integer},
-		{Name: "rcol4", Type: sql.BigInteger},
+		{Name: "lcol1", Type: sql.String, Source: "left"},
+		{Name: "lcol2", Type: sql.String, Source: "left"},
+		{Name: "lcol3", Type: sql.Integer, Source: "left"},
+		{Name: "lcol4", Type: sql.BigInteger, Source: "left"},
+		{Name: "rcol1", Type: sql.String, Source: "right"},
+		{Name: "rcol2", Type: sql.String, Source: "right"},
+		{Name: "rcol3", Type: sql.Integer, Source: "right"},
+		{Name: "rcol4", Type: sql.BigInteger, Source: "right"},
 	}
 
 	ltable := mem.NewTable("left", lSchema)
@@ -0,0 +1,146 @@
+package plan
+
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// CreateTable creates a new table in a database implementing sql.Alterable.
+type CreateTable struct {
+	// Database is the database in which the table is created.
+	Database sql.Database
+	// Name is the name of the new table.
+	Name string
+	// Columns is the schema of the new table.
+	Columns sql.Schema
+	// IfNotExists is true if an existing table with the same name is not
+	// an error.
+	IfNotExists bool
+}
+
+// NewCreateTable creates a new CreateTable node.
+func NewCreateTable(db sql.Database, name string, columns sql.Schema, ifNotExists bool) *CreateTable {
+	return &CreateTable{
+		Database:    db,
+		Name:        name,
+		Columns:     columns,
+		IfNotExists: ifNotExists,
+	}
+}
+
+func (p *CreateTable) Resolved() bool {
+	_, ok := p.Database.(*sql.UnresolvedDatabase)
+	return !ok
+}
+
+func (*CreateTable) Children() []sql.Node {
+	return nil
+}
+
+func (*CreateTable) Schema() sql.Schema {
+	return sql.Schema{}
+}
+
+// Execute creates the table.
+func (p *CreateTable) Execute() (sql.Result, error) {
+	db, err := alterable(p.Database)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	if _, ok := db.Tables()[p.Name]; ok {
+		if p.IfNotExists {
+			return sql.Result{}, nil
+		}
+
+		return sql.Result{}, fmt.Errorf("table already exists: %s", p.Name)
+	}
+
+	return sql.Result{}, db.Create(p.Name, p.Columns)
+}
+
+func (p *CreateTable) RowIter() (sql.RowIter, error) {
+	return executeToRowIter(p)
+}
+
+func (p *CreateTable) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	n := *p
+	return f(&n)
+}
+
+func (p *CreateTable) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	return p
+}
+
+// DropTable removes a table from a database implementing sql.Alterable.
+type DropTable struct {
+	// Database is the database the table is removed from.
+	Database sql.Database
+	// Name is the name of the table to remove.
+	Name string
+	// IfExists is true if a missing table is not an error.
+	IfExists bool
+}
+
+// NewDropTable creates a new DropTable node.
+func NewDropTable(db sql.Database, name string, ifExists bool) *DropTable {
+	return &DropTable{
+		Database: db,
+		Name:     name,
+		IfExists: ifExists,
+	}
+}
+
+func (p *DropTable) Resolved() bool {
+	_, ok := p.Database.(*sql.UnresolvedDatabase)
+	return !ok
+}
+
+func (*DropTable) Children() []sql.Node {
+	return nil
+}
+
+func (*DropTable) Schema() sql.Schema {
+	return sql.Schema{}
+}
+
+// Execute removes the table.
+func (p *DropTable) Execute() (sql.Result, error) {
+	db, err := alterable(p.Database)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	if _, ok := db.Tables()[p.Name]; !ok {
+		if p.IfExists {
+			return sql.Result{}, nil
+		}
+
+		return sql.Result{}, fmt.Errorf("table not found: %s", p.Name)
+	}
+
+	return sql.Result{}, db.Drop(p.Name)
+}
+
+func (p *DropTable) RowIter() (sql.RowIter, error) {
+	return executeToRowIter(p)
+}
+
+func (p *DropTable) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	n := *p
+	return f(&n)
+}
+
+func (p *DropTable) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	return p
+}
+
+func alterable(db sql.Database) (sql.Alterable, error) {
+	a, ok := db.(sql.Alterable)
+	if !ok {
+		return nil, fmt.Errorf("tables of database %s can't be altered", db.Name())
+	}
+
+	return a, nil
+}
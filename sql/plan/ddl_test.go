@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTable(t *testing.T) {
+	require := require.New(t)
+
+	db := mem.NewDatabase("db")
+	schema := sql.Schema{
+		{Name: "a", Type: sql.Integer},
+		{Name: "b", Type: sql.String, Nullable: true, Default: "x"},
+	}
+
+	create := NewCreateTable(db, "t", schema, false)
+	require.True(create.Resolved())
+
+	_, err := create.Execute()
+	require.NoError(err)
+
+	table, ok := db.Tables()["t"]
+	require.True(ok)
+	require.Len(table.Schema(), 2)
+	require.Equal("x", table.Schema()[1].Default)
+	require.True(table.Schema()[1].Nullable)
+
+	_, err = create.Execute()
+	require.Error(err)
+
+	_, err = NewCreateTable(db, "t", schema, true).Execute()
+	require.NoError(err)
+
+	require.False(NewCreateTable(&sql.UnresolvedDatabase{}, "t", schema, false).Resolved())
+}
+
+func TestDropTable(t *testing.T) {
+	require := require.New(t)
+
+	db := mem.NewDatabase("db")
+	db.AddTable("t", mem.NewTable("t", sql.Schema{{Name: "a", Type: sql.Integer}}))
+
+	_, err := NewDropTable(db, "t", false).Execute()
+	require.NoError(err)
+	require.Len(db.Tables(), 0)
+
+	_, err = NewDropTable(db, "t", false).Execute()
+	require.Error(err)
+
+	rows, err := sql.NodeToRows(NewDropTable(db, "t", true))
+	require.NoError(err)
+	require.Len(rows, 0)
+}
+
+func TestDDL_NotAlterable(t *testing.T) {
+	require := require.New(t)
+
+	db := &sql.UnresolvedDatabase{}
+	_, err := NewCreateTable(db, "t", nil, false).Execute()
+	require.Error(err)
+
+	_, err = NewDropTable(db, "t", true).Execute()
+	require.Error(err)
+}
@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// Delete removes the rows returned by its child, which reads them from a
+// table implementing sql.Deleter.
+type Delete struct {
+	UnaryNode
+}
+
+// NewDelete creates a new Delete node.
+func NewDelete(child sql.Node) *Delete {
+	return &Delete{UnaryNode{Child: child}}
+}
+
+func (p *Delete) Schema() sql.Schema {
+	return sql.Schema{{
+		Name:     "deleted",
+		Type:     sql.BigInteger,
+		Default:  int64(0),
+		Nullable: false,
+	}}
+}
+
+// Execute deletes the rows and returns the number of rows deleted.
+func (p *Delete) Execute() (sql.Result, error) {
+	var result sql.Result
+	table, err := findTable(p.Child)
+	if err != nil {
+		return result, err
+	}
+
+	deleter, ok := table.(sql.Deleter)
+	if !ok {
+		return result, fmt.Errorf("table %s does not support DELETE", table.Name())
+	}
+
+	// All the rows are read before deleting any of them, so the table is
+	// not modified while it's being read.
+	rows, err := sql.NodeToRows(p.Child)
+	if err != nil {
+		return result, err
+	}
+
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	if err := deleter.Delete(rows); err != nil {
+		return result, err
+	}
+
+	result.RowsAffected = int64(len(rows))
+	return result, nil
+}
+
+func (p *Delete) RowIter() (sql.RowIter, error) {
+	result, err := p.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(sql.NewRow(result.RowsAffected)), nil
+}
+
+func (p *Delete) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.UnaryNode.Child.TransformUp(f)
+	return f(NewDelete(c))
+}
+
+func (p *Delete) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.UnaryNode.Child.TransformExpressionsUp(f)
+	return NewDelete(c)
+}
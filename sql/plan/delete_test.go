@@ -0,0 +1,51 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelete(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+	})
+	for i := int64(1); i <= 4; i++ {
+		require.NoError(table.Insert(sql.NewRow(i)))
+	}
+
+	a := expression.NewGetFieldWithTable(0, sql.BigInteger, "t", "a", false)
+	del := NewDelete(NewLimit(1, NewFilter(
+		expression.NewGreaterThan(a, expression.NewLiteral(int64(2), sql.BigInteger)),
+		NewTableAlias("x", table),
+	)))
+	require.Equal("deleted", del.Schema()[0].Name)
+
+	result, err := del.Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 1}, result)
+
+	rows, err := sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		sql.NewRow(int64(1)),
+		sql.NewRow(int64(2)),
+		sql.NewRow(int64(4)),
+	}, rows)
+
+	rows, err = sql.NodeToRows(NewDelete(table))
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(3))}, rows)
+	require.Equal(int64(0), table.EstimatedRowCount())
+}
+
+func TestDelete_NotDeletable(t *testing.T) {
+	_, err := NewDelete(NewShowTables(nil)).Execute()
+	require.Error(t, err)
+}
@@ -0,0 +1,247 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+)
+
+// distinctPartitions is the number of partitions rows are spilled to.
+const distinctPartitions = 16
+
+// Distinct removes duplicated rows from its child, keeping the order of their
+// first occurrence. Rows are returned as soon as they are seen for the first
+// time, keeping a hash set of the returned rows. Once the set exceeds
+// MemoryBudget, the rest of the rows are spilled to disk with their position,
+// partitioned by their hash. After the child is exhausted, each partition is
+// deduplicated on its own and the partitions are merged back by position.
+type Distinct struct {
+	UnaryNode
+	// MemoryBudget is the maximum number of bytes of the set of returned
+	// rows kept in memory.
+	MemoryBudget int
+}
+
+// NewDistinct creates a new Distinct node.
+func NewDistinct(child sql.Node) *Distinct {
+	return &Distinct{
+		UnaryNode:    UnaryNode{Child: child},
+		MemoryBudget: DefaultMemoryBudget,
+	}
+}
+
+func (d *Distinct) RowIter() (sql.RowIter, error) {
+	it, err := d.Child.RowIter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &distinctIter{
+		childIter: it,
+		width:     len(d.Child.Schema()),
+		budget:    d.MemoryBudget,
+		seen:      make(map[string]struct{}),
+	}, nil
+}
+
+func (d *Distinct) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := d.UnaryNode.Child.TransformUp(f)
+	n := NewDistinct(c)
+	n.MemoryBudget = d.MemoryBudget
+
+	return f(n)
+}
+
+func (d *Distinct) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := d.UnaryNode.Child.TransformExpressionsUp(f)
+	n := NewDistinct(c)
+	n.MemoryBudget = d.MemoryBudget
+
+	return n
+}
+
+type distinctIter struct {
+	childIter sql.RowIter
+	width     int
+	budget    int
+	seen      map[string]struct{}
+	size      int
+	seq       int64
+
+	partitions     spillPartitions
+	deduplicated   []*spillFile
+	spilled        sql.RowIter
+	childExhausted bool
+}
+
+func (i *distinctIter) Next() (sql.Row, error) {
+	row, err := i.next()
+	if err != nil && err != io.EOF {
+		_ = i.closeSpilled()
+	}
+
+	return row, err
+}
+
+func (i *distinctIter) next() (sql.Row, error) {
+	for !i.childExhausted {
+		row, err := i.childIter.Next()
+		if err == io.EOF {
+			i.childExhausted = true
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		i.seq++
+		key := rowKey(row)
+		if _, ok := i.seen[key]; ok {
+			continue
+		}
+
+		if i.partitions == nil && i.size < i.budget {
+			i.seen[key] = struct{}{}
+			i.size += len(key) + 16
+			return row, nil
+		}
+
+		if err := i.spill(key, row); err != nil {
+			return nil, err
+		}
+	}
+
+	if i.partitions == nil {
+		return nil, io.EOF
+	}
+
+	if i.spilled == nil {
+		it, err := i.mergeSpilled()
+		if err != nil {
+			return nil, err
+		}
+
+		i.spilled = it
+	}
+
+	row, err := i.spilled.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return row[:i.width], nil
+}
+
+// spill writes the row followed by its position to the partition of its key.
+func (i *distinctIter) spill(key string, row sql.Row) error {
+	if i.partitions == nil {
+		p, err := newSpillPartitions(distinctPartitions)
+		if err != nil {
+			return err
+		}
+
+		i.partitions = p
+	}
+
+	return i.partitions.Write(key, append(row.Copy(), i.seq))
+}
+
+// mergeSpilled deduplicates each partition into a new file and returns an
+// iterator merging them by position. Rows of different partitions have
+// different keys, so only the rows of one partition need to be kept in
+// memory at a time.
+func (i *distinctIter) mergeSpilled() (sql.RowIter, error) {
+	var iters []sql.RowIter
+	for _, p := range i.partitions {
+		f, err := newSpillFile()
+		if err != nil {
+			return nil, err
+		}
+
+		i.deduplicated = append(i.deduplicated, f)
+		if err := i.deduplicate(p, f); err != nil {
+			return nil, err
+		}
+
+		it, err := f.RowIter()
+		if err != nil {
+			return nil, err
+		}
+
+		iters = append(iters, it)
+	}
+
+	seq := []SortField{{
+		Column: expression.NewGetField(i.width, sql.BigInteger, "seq", false),
+		Order:  Ascending,
+	}}
+
+	return newMergeIter(seq, iters)
+}
+
+// deduplicate writes the first occurrence of each row of the partition to
+// the file, in the same order.
+func (i *distinctIter) deduplicate(p *spillFile, f *spillFile) error {
+	it, err := p.RowIter()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]struct{})
+	for {
+		row, err := it.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		key := rowKey(row[:i.width])
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+		if err := f.Write(row); err != nil {
+			return err
+		}
+	}
+}
+
+func (i *distinctIter) closeSpilled() error {
+	var err error
+	if i.partitions != nil {
+		err = i.partitions.Close()
+		i.partitions = nil
+	}
+
+	for _, f := range i.deduplicated {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	i.deduplicated = nil
+	return err
+}
+
+func (i *distinctIter) Close() error {
+	i.seen = nil
+
+	err := i.closeSpilled()
+	if cerr := i.childIter.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// rowKey returns a string that is equal for rows with equal values.
+func rowKey(row sql.Row) string {
+	return fmt.Sprintf("%#v", row)
+}
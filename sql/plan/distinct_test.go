@@ -0,0 +1,110 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistinct(t *testing.T) {
+	require := require.New(t)
+
+	child := mem.NewTable("test", sql.Schema{
+		{Name: "a", Type: sql.String, Nullable: true},
+		{Name: "b", Type: sql.Integer},
+	})
+
+	rows := []sql.Row{
+		sql.NewRow("a", int32(1)),
+		sql.NewRow("b", int32(1)),
+		sql.NewRow("a", int32(1)),
+		sql.NewRow(nil, int32(2)),
+		sql.NewRow("a", int32(2)),
+		sql.NewRow(nil, int32(2)),
+		sql.NewRow("b", int32(1)),
+	}
+
+	for _, r := range rows {
+		require.NoError(child.Insert(r))
+	}
+
+	expected := []sql.Row{
+		sql.NewRow("a", int32(1)),
+		sql.NewRow("b", int32(1)),
+		sql.NewRow(nil, int32(2)),
+		sql.NewRow("a", int32(2)),
+	}
+
+	d := NewDistinct(child)
+	require.Equal(child.Schema(), d.Schema())
+
+	result, err := sql.NodeToRows(d)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	// with a single row in memory, the rest of them are spilled to disk and
+	// still returned in the same order
+	before := spillFiles(t)
+
+	d.MemoryBudget = 1
+	result, err = sql.NodeToRows(d)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	require.Equal(before, spillFiles(t))
+}
+
+func TestDistinct_SpillOrder(t *testing.T) {
+	require := require.New(t)
+
+	child := mem.NewTable("test", sql.Schema{{Name: "a", Type: sql.Integer}})
+	var expected []sql.Row
+	for i := 0; i < 200; i++ {
+		row := sql.NewRow(int32((i * 7) % 50))
+		require.NoError(child.Insert(row))
+		if i < 50 {
+			expected = append(expected, row)
+		}
+	}
+
+	before := spillFiles(t)
+
+	d := NewDistinct(child)
+	d.MemoryBudget = 100
+	result, err := sql.NodeToRows(d)
+	require.NoError(err)
+	require.Equal(expected, result)
+
+	require.Equal(before, spillFiles(t))
+}
+
+func TestDistinct_TransformUp(t *testing.T) {
+	require := require.New(t)
+
+	d := NewDistinct(NewUnresolvedTable("foo"))
+	d.MemoryBudget = 5
+
+	table := mem.NewTable("foo", sql.Schema{})
+	n := d.TransformUp(func(n sql.Node) sql.Node {
+		if _, ok := n.(*UnresolvedTable); ok {
+			return table
+		}
+
+		return n
+	})
+
+	expected := NewDistinct(table)
+	expected.MemoryBudget = 5
+	require.Equal(expected, n)
+}
+
+func spillFiles(t *testing.T) []string {
+	files, err := filepath.Glob(filepath.Join(os.TempDir(), "sqle-spill-*"))
+	require.NoError(t, err)
+	return files
+}
@@ -0,0 +1,29 @@
+package plan
+
+import "gopkg.in/sqle/sqle.v0/sql"
+
+// EmptyResult returns no rows, with the schema of its child. The child is
+// never read, but it's kept so the table of the node can still be found,
+// such as in an UPDATE with a condition that is never true.
+type EmptyResult struct {
+	UnaryNode
+}
+
+// NewEmptyResult creates a new EmptyResult node.
+func NewEmptyResult(child sql.Node) *EmptyResult {
+	return &EmptyResult{UnaryNode{child}}
+}
+
+func (p *EmptyResult) RowIter() (sql.RowIter, error) {
+	return sql.RowsToRowIter(), nil
+}
+
+func (p *EmptyResult) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewEmptyResult(c))
+}
+
+func (p *EmptyResult) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewEmptyResult(c)
+}
@@ -0,0 +1,32 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmptyResult(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+	})
+	require.NoError(table.Insert(sql.NewRow(int64(1))))
+
+	node := NewEmptyResult(table)
+	require.Equal(table.Schema(), node.Schema())
+
+	rows, err := sql.NodeToRows(node)
+	require.NoError(err)
+	require.Len(rows, 0)
+
+	// the rows of the table are not modified
+	result, err := NewDelete(node).Execute()
+	require.NoError(err)
+	require.Equal(int64(0), result.RowsAffected)
+	require.Equal(int64(1), table.EstimatedRowCount())
+}
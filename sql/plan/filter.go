@@ -4,18 +4,18 @@ import "gopkg.in/sqle/sqle.v0/sql"
 
 type Filter struct {
 	UnaryNode
-	expression sql.Expression
+	Expression sql.Expression
 }
 
 func NewFilter(expression sql.Expression, child sql.Node) *Filter {
 	return &Filter{
 		UnaryNode:  UnaryNode{Child: child},
-		expression: expression,
+		Expression: expression,
 	}
 }
 
 func (p *Filter) Resolved() bool {
-	return p.UnaryNode.Child.Resolved() && p.expression.Resolved()
+	return p.UnaryNode.Child.Resolved() && p.Expression.Resolved()
 }
 
 func (p *Filter) RowIter() (sql.RowIter, error) {
@@ -28,14 +28,14 @@ func (p *Filter) RowIter() (sql.RowIter, error) {
 
 func (p *Filter) TransformUp(f func(sql.Node) sql.Node) sql.Node {
 	c := p.UnaryNode.Child.TransformUp(f)
-	n := NewFilter(p.expression, c)
+	n := NewFilter(p.Expression, c)
 
 	return f(n)
 }
 
 func (p *Filter) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
 	c := p.UnaryNode.Child.TransformExpressionsUp(f)
-	e := p.expression.TransformUp(f)
+	e := p.Expression.TransformUp(f)
 	n := NewFilter(e, c)
 
 	return n
@@ -49,10 +49,16 @@ type filterIter struct {
 func (i *filterIter) Next() (sql.Row, error) {
 	for {
 		row, err := i.childIter.Next()
+		if err != nil {
+			return nil, err
+		}
 
+		result, err := i.f.Expression.Eval(row)
 		if err != nil {
 			return nil, err
-		} else if i.f.expression.Eval(row) == true {
+		}
+
+		if result == true {
 			return row, nil
 		}
 	}
@@ -9,31 +9,43 @@ import (
 	"gopkg.in/sqle/sqle.v0/sql/expression"
 )
 
+// groupByPartitions is the number of partitions groups are spilled to.
+const groupByPartitions = 16
+
+// GroupBy groups the rows of its child by the Grouping expressions and
+// computes the Aggregate expressions for each group. The aggregation buffers
+// of the groups are updated as rows are read. Once the groups exceed
+// MemoryBudget, their buffers are spilled to disk, partitioned by the
+// grouping key, and the partial buffers of each partition are merged after
+// the child is exhausted.
 type GroupBy struct {
 	UnaryNode
-	aggregate []sql.Expression
-	grouping  []sql.Expression
+	Aggregate []sql.Expression
+	Grouping  []sql.Expression
+	// MemoryBudget is the maximum number of bytes of groups kept in memory.
+	MemoryBudget int
 }
 
 func NewGroupBy(aggregate []sql.Expression, grouping []sql.Expression,
 	child sql.Node) *GroupBy {
 
 	return &GroupBy{
-		UnaryNode: UnaryNode{Child: child},
-		aggregate: aggregate,
-		grouping:  grouping,
+		UnaryNode:    UnaryNode{Child: child},
+		Aggregate:    aggregate,
+		Grouping:     grouping,
+		MemoryBudget: DefaultMemoryBudget,
 	}
 }
 
 func (p *GroupBy) Resolved() bool {
 	return p.UnaryNode.Child.Resolved() &&
-		expressionsResolved(p.aggregate...) &&
-		expressionsResolved(p.grouping...)
+		expressionsResolved(p.Aggregate...) &&
+		expressionsResolved(p.Grouping...)
 }
 
 func (p *GroupBy) Schema() sql.Schema {
 	s := sql.Schema{}
-	for _, e := range p.aggregate {
+	for _, e := range p.Aggregate {
 		s = append(s, &sql.Column{
 			Name:     e.Name(),
 			Type:     e.Type(),
@@ -54,131 +66,276 @@ func (p *GroupBy) RowIter() (sql.RowIter, error) {
 
 func (p *GroupBy) TransformUp(f func(sql.Node) sql.Node) sql.Node {
 	c := p.UnaryNode.Child.TransformUp(f)
-	n := NewGroupBy(p.aggregate, p.grouping, c)
+	n := NewGroupBy(p.Aggregate, p.Grouping, c)
+	n.MemoryBudget = p.MemoryBudget
 
 	return f(n)
 }
 
 func (p *GroupBy) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
 	c := p.UnaryNode.Child.TransformExpressionsUp(f)
-	aes := transformExpressionsUp(f, p.aggregate)
-	ges := transformExpressionsUp(f, p.grouping)
+	aes := transformExpressionsUp(f, p.Aggregate)
+	ges := transformExpressionsUp(f, p.Grouping)
 	n := NewGroupBy(aes, ges, c)
+	n.MemoryBudget = p.MemoryBudget
 
 	return n
 }
 
 type groupByIter struct {
 	p         *GroupBy
+	aggs      []sql.AggregationExpression
 	childIter sql.RowIter
-	rows      []sql.Row
-	idx       int
+	computed  bool
+
+	groups map[string][]sql.Row
+	keys   []string
+	size   int
+
+	rows []sql.Row
+	idx  int
+
+	partitions spillPartitions
+	partition  int
 }
 
 func newGroupByIter(p *GroupBy, child sql.RowIter) *groupByIter {
 	return &groupByIter{
 		p:         p,
+		aggs:      exprsToAggregateExprs(p.Aggregate),
 		childIter: child,
-		rows:      nil,
-		idx:       -1,
+		groups:    make(map[string][]sql.Row),
 	}
 }
 
 func (i *groupByIter) Next() (sql.Row, error) {
-	if i.idx == -1 {
-		err := i.computeRows()
-		if err != nil {
+	row, err := i.next()
+	if err != nil && err != io.EOF {
+		_ = i.closePartitions()
+	}
+
+	return row, err
+}
+
+func (i *groupByIter) next() (sql.Row, error) {
+	if !i.computed {
+		if err := i.computeGroups(); err != nil {
 			return nil, err
 		}
-		i.idx = 0
+
+		i.computed = true
 	}
-	if i.idx >= len(i.rows) {
-		return nil, io.EOF
+
+	for i.idx >= len(i.rows) {
+		if i.partition >= len(i.partitions) {
+			return nil, io.EOF
+		}
+
+		if err := i.mergePartition(); err != nil {
+			return nil, err
+		}
 	}
+
 	row := i.rows[i.idx]
 	i.idx++
 	return row, nil
 }
 
 func (i *groupByIter) Close() error {
+	i.groups = nil
 	i.rows = nil
-	return i.childIter.Close()
+
+	err := i.closePartitions()
+	if cerr := i.childIter.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+func (i *groupByIter) closePartitions() error {
+	var err error
+	if i.partitions != nil {
+		err = i.partitions.Close()
+		i.partitions = nil
+	}
+
+	return err
 }
 
-func (i *groupByIter) computeRows() error {
-	rows := []sql.Row{}
+// computeGroups reads all the rows of the child, updating the buffers of
+// their groups. If the groups were spilled to disk, the ones left in memory
+// are spilled too, so each partition has all the partial buffers of its
+// groups.
+func (i *groupByIter) computeGroups() error {
 	for {
-		childRow, err := i.childIter.Next()
+		row, err := i.childIter.Next()
 		if err == io.EOF {
 			break
 		}
+
+		if err != nil {
+			return err
+		}
+
+		key, err := groupingKey(i.p.Grouping, row)
 		if err != nil {
 			return err
 		}
-		rows = append(rows, childRow)
+
+		// buffers such as the sets of COUNT(DISTINCT) grow as they're
+		// updated, so they are measured again after every update.
+		buffers := i.group(key)
+		for j, agg := range i.aggs {
+			size := rowSize(buffers[j])
+			if err := agg.Update(buffers[j], row); err != nil {
+				return err
+			}
+
+			i.size += rowSize(buffers[j]) - size
+		}
+
+		if i.size > i.p.MemoryBudget {
+			if err := i.spill(); err != nil {
+				return err
+			}
+		}
 	}
 
-	rows, err := groupBy(rows, i.p.aggregate, i.p.grouping)
-	if err != nil {
-		return err
+	if i.partitions != nil {
+		return i.spill()
 	}
 
-	i.rows = rows
-	return nil
+	// an aggregation without grouping returns a row even with no input
+	if len(i.keys) == 0 && len(i.p.Grouping) == 0 {
+		i.group("")
+	}
+
+	return i.evalGroups()
 }
 
-func groupBy(rows []sql.Row, aggExpr []sql.Expression,
-	groupExpr []sql.Expression) ([]sql.Row, error) {
+// group returns the buffers of the group with the given key, creating them if
+// it's not in memory.
+func (i *groupByIter) group(key string) []sql.Row {
+	if buffers, ok := i.groups[key]; ok {
+		return buffers
+	}
 
-	//TODO: currently, we first group all rows, and then
-	//      compute aggregations in a separate stage. We should
-	//      compute aggregations incrementally instead.
+	buffers := make([]sql.Row, len(i.aggs))
+	i.size += len(key)
+	for j, agg := range i.aggs {
+		buffers[j] = agg.NewBuffer()
+		i.size += rowSize(buffers[j])
+	}
 
-	hrows := map[interface{}][]sql.Row{}
-	for _, row := range rows {
-		key := groupingKey(groupExpr, row)
-		hrows[key] = append(hrows[key], row)
+	i.groups[key] = buffers
+	i.keys = append(i.keys, key)
+	return buffers
+}
+
+func (i *groupByIter) resetGroups() {
+	i.groups = make(map[string][]sql.Row)
+	i.keys = nil
+	i.size = 0
+}
+
+// spill writes the groups in memory to the partitions of their keys, as
+// rows with the key followed by the values of all their buffers.
+func (i *groupByIter) spill() error {
+	if i.partitions == nil {
+		p, err := newSpillPartitions(groupByPartitions)
+		if err != nil {
+			return err
+		}
+
+		i.partitions = p
 	}
 
-	result := make([]sql.Row, 0, len(hrows))
-	for _, rows := range hrows {
-		row := aggregate(aggExpr, rows)
-		result = append(result, row)
+	for _, key := range i.keys {
+		row := sql.Row{key}
+		for _, buffer := range i.groups[key] {
+			row = append(row, buffer...)
+		}
+
+		if err := i.partitions.Write(key, row); err != nil {
+			return err
+		}
 	}
 
-	return result, nil
+	i.resetGroups()
+	return nil
 }
 
-func groupingKey(exprs []sql.Expression, row sql.Row) interface{} {
-	//TODO: use a more robust/efficient way of calculating grouping keys.
-	vals := make([]string, 0, len(exprs))
-	for _, expr := range exprs {
-		vals = append(vals, fmt.Sprintf("%#v", expr.Eval(row)))
+// mergePartition merges the partial buffers of the groups in the next
+// partition. Groups of different partitions have different keys, so only
+// the groups of the current partition need to be kept in memory.
+func (i *groupByIter) mergePartition() error {
+	i.resetGroups()
+
+	iter, err := i.partitions[i.partition].RowIter()
+	if err != nil {
+		return err
 	}
 
-	return strings.Join(vals, ",")
-}
+	for {
+		row, err := iter.Next()
+		if err == io.EOF {
+			break
+		}
 
-func aggregate(exprs []sql.Expression, rows []sql.Row) sql.Row {
-	aggs := exprsToAggregateExprs(exprs)
+		if err != nil {
+			return err
+		}
 
-	buffers := make([]sql.Row, len(aggs))
-	for i, agg := range aggs {
-		buffers[i] = agg.NewBuffer()
+		buffers := i.group(row[0].(string))
+		partial := row[1:]
+		for j, agg := range i.aggs {
+			n := len(buffers[j])
+			agg.Merge(buffers[j], partial[:n])
+			partial = partial[n:]
+		}
 	}
 
-	for _, row := range rows {
-		for i, agg := range aggs {
-			agg.Update(buffers[i], row)
+	i.partition++
+	return i.evalGroups()
+}
+
+// evalGroups evaluates the aggregations of the groups in memory, in the
+// order the groups were created.
+func (i *groupByIter) evalGroups() error {
+	i.rows = make([]sql.Row, 0, len(i.keys))
+	i.idx = 0
+	for _, key := range i.keys {
+		buffers := i.groups[key]
+		fields := make([]interface{}, 0, len(i.aggs))
+		for j, agg := range i.aggs {
+			f, err := agg.Eval(buffers[j])
+			if err != nil {
+				return err
+			}
+
+			fields = append(fields, f)
 		}
+
+		i.rows = append(i.rows, sql.NewRow(fields...))
 	}
 
-	fields := make([]interface{}, 0, len(exprs))
-	for i, agg := range aggs {
-		fields = append(fields, agg.Eval(buffers[i]))
+	return nil
+}
+
+func groupingKey(exprs []sql.Expression, row sql.Row) (string, error) {
+	//TODO: use a more robust/efficient way of calculating grouping keys.
+	vals := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		v, err := expr.Eval(row)
+		if err != nil {
+			return "", err
+		}
+
+		vals = append(vals, fmt.Sprintf("%#v", v))
 	}
 
-	return sql.NewRow(fields...)
+	return strings.Join(vals, ","), nil
 }
 
 func exprsToAggregateExprs(exprs []sql.Expression) []sql.AggregationExpression {
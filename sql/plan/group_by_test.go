@@ -1,6 +1,7 @@
 package plan
 
 import (
+	"fmt"
 	"testing"
 
 	"gopkg.in/sqle/sqle.v0/mem"
@@ -86,3 +87,111 @@ func TestGroupBy_RowIter(t *testing.T) {
 	assert.Equal(sql.NewRow("col1_1", int64(1111)), rows[0])
 	assert.Equal(sql.NewRow("col1_2", int64(4444)), rows[1])
 }
+
+func TestGroupBy_EmptyInput(t *testing.T) {
+	assert := assert.New(t)
+
+	child := mem.NewTable("test", sql.Schema{{Name: "col1", Type: sql.String}})
+	count := expression.NewCount(expression.NewStar())
+
+	rows, err := sql.NodeToRows(NewGroupBy([]sql.Expression{count}, nil, child))
+	assert.NoError(err)
+	assert.Equal([]sql.Row{sql.NewRow(int32(0))}, rows)
+
+	rows, err = sql.NodeToRows(NewGroupBy(
+		[]sql.Expression{count},
+		[]sql.Expression{expression.NewGetField(0, sql.String, "col1", false)},
+		child,
+	))
+	assert.NoError(err)
+	assert.Empty(rows)
+}
+
+func TestGroupBy_Spill(t *testing.T) {
+	assert := assert.New(t)
+
+	childSchema := sql.Schema{
+		{Name: "col1", Type: sql.String},
+		{Name: "col2", Type: sql.BigInteger},
+	}
+	child := mem.NewTable("test", childSchema)
+	for i := 0; i < 100; i++ {
+		assert.NoError(child.Insert(sql.NewRow(fmt.Sprint(i%10), int64(i%3))))
+	}
+
+	col1 := expression.NewGetField(0, sql.String, "col1", false)
+	col2 := expression.NewGetField(1, sql.BigInteger, "col2", false)
+	gb := NewGroupBy(
+		[]sql.Expression{
+			col1,
+			expression.NewCount(expression.NewStar()),
+			expression.NewCountDistinct(col2),
+		},
+		[]sql.Expression{col1},
+		child,
+	)
+
+	before := spillFiles(t)
+
+	expected, err := sql.NodeToRows(gb)
+	assert.NoError(err)
+	assert.Len(expected, 10)
+	assert.Equal(sql.NewRow("0", int32(10), int32(3)), expected[0])
+
+	// with a single group in memory, buffers are spilled for every row
+	gb.MemoryBudget = 1
+	rows, err := sql.NodeToRows(gb)
+	assert.NoError(err)
+	assert.ElementsMatch(expected, rows)
+
+	assert.Equal(before, spillFiles(t))
+}
+
+func TestGroupBy_SpillDistinct(t *testing.T) {
+	assert := assert.New(t)
+
+	child := mem.NewTable("test", sql.Schema{{Name: "col1", Type: sql.BigInteger}})
+	for i := 0; i < 100; i++ {
+		assert.NoError(child.Insert(sql.NewRow(int64(i))))
+	}
+
+	col1 := expression.NewGetField(0, sql.BigInteger, "col1", false)
+	gb := NewGroupBy(
+		[]sql.Expression{expression.NewCountDistinct(col1)},
+		nil,
+		child,
+	)
+	gb.MemoryBudget = 1024
+
+	iter, err := gb.RowIter()
+	assert.NoError(err)
+
+	// the single group only outgrows the budget as values are added to it
+	row, err := iter.Next()
+	assert.NoError(err)
+	assert.Equal(sql.NewRow(int32(100)), row)
+	assert.NotNil(iter.(*groupByIter).partitions)
+
+	assert.NoError(iter.Close())
+}
+
+func TestGroupBy_TransformUp(t *testing.T) {
+	assert := assert.New(t)
+
+	agg := []sql.Expression{expression.NewCount(expression.NewStar())}
+	gb := NewGroupBy(agg, nil, NewUnresolvedTable("foo"))
+	gb.MemoryBudget = 5
+
+	table := mem.NewTable("foo", sql.Schema{})
+	n := gb.TransformUp(func(n sql.Node) sql.Node {
+		if _, ok := n.(*UnresolvedTable); ok {
+			return table
+		}
+
+		return n
+	})
+
+	expected := NewGroupBy(agg, nil, table)
+	expected.MemoryBudget = 5
+	assert.Equal(expected, n)
+}
@@ -0,0 +1,229 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+)
+
+// HashJoin is an inner join of the rows of both sides whose keys are equal.
+// LeftKey is evaluated against the rows of the left side and RightKey against
+// the rows of the right side. A hash table is built with the rows of the
+// smaller side, which is then probed with the rows of the bigger one.
+// Rows with a NULL key never match.
+type HashJoin struct {
+	BinaryNode
+	LeftKey  sql.Expression
+	RightKey sql.Expression
+}
+
+// NewHashJoin creates a new HashJoin node.
+func NewHashJoin(left, right sql.Node, leftKey, rightKey sql.Expression) *HashJoin {
+	return &HashJoin{
+		BinaryNode: BinaryNode{Left: left, Right: right},
+		LeftKey:    leftKey,
+		RightKey:   rightKey,
+	}
+}
+
+func (j *HashJoin) Schema() sql.Schema {
+	return append(append(sql.Schema{}, j.Left.Schema()...), j.Right.Schema()...)
+}
+
+func (j *HashJoin) Resolved() bool {
+	return j.Left.Resolved() && j.Right.Resolved() &&
+		j.LeftKey.Resolved() && j.RightKey.Resolved()
+}
+
+func (j *HashJoin) RowIter() (sql.RowIter, error) {
+	li, err := j.Left.RowIter()
+	if err != nil {
+		return nil, err
+	}
+
+	ri, err := j.Right.RowIter()
+	if err != nil {
+		_ = li.Close()
+		return nil, err
+	}
+
+	return &hashJoinIter{j: j, li: li, ri: ri}, nil
+}
+
+func (j *HashJoin) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	ln := j.Left.TransformUp(f)
+	rn := j.Right.TransformUp(f)
+
+	return f(NewHashJoin(ln, rn, j.LeftKey, j.RightKey))
+}
+
+func (j *HashJoin) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	ln := j.Left.TransformExpressionsUp(f)
+	rn := j.Right.TransformExpressionsUp(f)
+
+	return NewHashJoin(ln, rn, j.LeftKey.TransformUp(f), j.RightKey.TransformUp(f))
+}
+
+type hashJoinIter struct {
+	j  *HashJoin
+	li sql.RowIter
+	ri sql.RowIter
+
+	built     bool
+	buildLeft bool
+	table     map[string][]sql.Row
+
+	// probe rows read while looking for the smaller side
+	pending    []sql.Row
+	probeIter  sql.RowIter
+	probeRow   sql.Row
+	matches    []sql.Row
+	matchIndex int
+}
+
+func (i *hashJoinIter) Next() (sql.Row, error) {
+	if !i.built {
+		if err := i.build(); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		if i.matchIndex < len(i.matches) {
+			m := i.matches[i.matchIndex]
+			i.matchIndex++
+
+			if i.buildLeft {
+				return joinRows(m, i.probeRow), nil
+			}
+
+			return joinRows(i.probeRow, m), nil
+		}
+
+		row, err := i.nextProbeRow()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok, err := i.key(row, !i.buildLeft)
+		if err != nil {
+			return nil, err
+		}
+
+		i.probeRow = row
+		i.matchIndex = 0
+		i.matches = nil
+		if ok {
+			i.matches = i.table[key]
+		}
+	}
+}
+
+func (i *hashJoinIter) nextProbeRow() (sql.Row, error) {
+	if len(i.pending) > 0 {
+		row := i.pending[0]
+		i.pending = i.pending[1:]
+		return row, nil
+	}
+
+	return i.probeIter.Next()
+}
+
+// build reads rows from both sides at the same pace until one of them is
+// exhausted, which is then used to build the hash table.
+func (i *hashJoinIter) build() error {
+	var lrows, rrows []sql.Row
+	var ldone, rdone bool
+	for !ldone && !rdone {
+		row, err := i.li.Next()
+		if err == io.EOF {
+			ldone = true
+		} else if err != nil {
+			return err
+		} else {
+			lrows = append(lrows, row)
+		}
+
+		if ldone {
+			break
+		}
+
+		row, err = i.ri.Next()
+		if err == io.EOF {
+			rdone = true
+		} else if err != nil {
+			return err
+		} else {
+			rrows = append(rrows, row)
+		}
+	}
+
+	var buildRows []sql.Row
+	if ldone {
+		i.buildLeft = true
+		buildRows = lrows
+		i.pending = rrows
+		i.probeIter = i.ri
+	} else {
+		buildRows = rrows
+		i.pending = lrows
+		i.probeIter = i.li
+	}
+
+	i.table = make(map[string][]sql.Row)
+	for _, row := range buildRows {
+		key, ok, err := i.key(row, i.buildLeft)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			i.table[key] = append(i.table[key], row)
+		}
+	}
+
+	i.built = true
+	return nil
+}
+
+// key returns the hash key of a row from the given side. Keys of both sides
+// are converted to the same type Equals compares them with, so numeric keys
+// are promoted to the widest of their types. It returns false if the key is
+// NULL, since it can't match any key of the other side, and fails like
+// Equals if the key can't be converted.
+func (i *hashJoinIter) key(row sql.Row, left bool) (string, bool, error) {
+	expr := i.j.RightKey
+	if left {
+		expr = i.j.LeftKey
+	}
+
+	v, err := expr.Eval(row)
+	if err != nil {
+		return "", false, err
+	}
+
+	if v == nil {
+		return "", false, nil
+	}
+
+	typ := expression.ComparisonType(i.j.LeftKey.Type(), i.j.RightKey.Type())
+	v, err = typ.Convert(v)
+	if err != nil {
+		return "", false, err
+	}
+
+	return fmt.Sprintf("%#v", v), true, nil
+}
+
+func (i *hashJoinIter) Close() error {
+	i.table = nil
+	i.pending = nil
+	if err := i.li.Close(); err != nil {
+		_ = i.ri.Close()
+		return err
+	}
+
+	return i.ri.Close()
+}
@@ -0,0 +1,107 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashJoin(t *testing.T) {
+	small := mem.NewTable("small", sql.Schema{
+		{Name: "id", Type: sql.BigInteger, Nullable: true},
+		{Name: "name", Type: sql.String},
+	})
+	big := mem.NewTable("big", sql.Schema{
+		{Name: "sid", Type: sql.Integer, Nullable: true},
+		{Name: "value", Type: sql.String},
+	})
+
+	require.NoError(t, small.Insert(sql.NewRow(int64(1), "a")))
+	require.NoError(t, small.Insert(sql.NewRow(nil, "b")))
+	require.NoError(t, big.Insert(sql.NewRow(int32(1), "x")))
+	require.NoError(t, big.Insert(sql.NewRow(int32(2), "y")))
+	require.NoError(t, big.Insert(sql.NewRow(nil, "z")))
+	require.NoError(t, big.Insert(sql.NewRow(int32(1), "w")))
+
+	var testCases = []struct {
+		name     string
+		node     sql.Node
+		expected []sql.Row
+	}{
+		{
+			"smaller left side",
+			NewHashJoin(small, big,
+				expression.NewGetField(0, sql.BigInteger, "id", true),
+				expression.NewGetField(0, sql.Integer, "sid", true),
+			),
+			[]sql.Row{
+				{int64(1), "a", int32(1), "x"},
+				{int64(1), "a", int32(1), "w"},
+			},
+		},
+		{
+			"smaller right side",
+			NewHashJoin(big, small,
+				expression.NewGetField(0, sql.Integer, "sid", true),
+				expression.NewGetField(0, sql.BigInteger, "id", true),
+			),
+			[]sql.Row{
+				{int32(1), "x", int64(1), "a"},
+				{int32(1), "w", int64(1), "a"},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			require.True(tt.node.Resolved())
+			require.Len(tt.node.Schema(), 4)
+
+			rows, err := sql.NodeToRows(tt.node)
+			require.NoError(err)
+			require.Equal(tt.expected, rows)
+		})
+	}
+}
+
+func TestHashJoin_Empty(t *testing.T) {
+	require := require.New(t)
+
+	left := mem.NewTable("left", lSchema)
+	right := mem.NewTable("right", rSchema)
+	insertData(assert.New(t), right)
+
+	j := NewHashJoin(left, right,
+		expression.NewGetField(0, sql.String, "lcol1", false),
+		expression.NewGetField(0, sql.String, "rcol1", false),
+	)
+
+	rows, err := sql.NodeToRows(j)
+	require.NoError(err)
+	require.Len(rows, 0)
+}
+
+func TestHashJoin_NotConvertibleKeys(t *testing.T) {
+	require := require.New(t)
+
+	left := mem.NewTable("left", sql.Schema{{Name: "i", Type: sql.Integer}})
+	right := mem.NewTable("right", sql.Schema{{Name: "s", Type: sql.String}})
+	require.NoError(left.Insert(sql.NewRow(int32(1))))
+	require.NoError(right.Insert(sql.NewRow("foo")))
+	require.NoError(right.Insert(sql.NewRow("1")))
+
+	j := NewHashJoin(left, right,
+		expression.NewGetField(0, sql.Integer, "i", false),
+		expression.NewGetField(0, sql.String, "s", false),
+	)
+
+	_, err := sql.NodeToRows(j)
+	require.Error(err)
+	require.Contains(err.Error(), `"foo"`)
+}
@@ -0,0 +1,37 @@
+package plan
+
+import "gopkg.in/sqle/sqle.v0/sql"
+
+// Having filters the rows of a grouping by the given condition. Unlike
+// Filter, its condition can contain aggregations, which are computed by the
+// GroupBy below it.
+type Having struct {
+	UnaryNode
+	Cond sql.Expression
+}
+
+// NewHaving creates a new Having node.
+func NewHaving(cond sql.Expression, child sql.Node) *Having {
+	return &Having{
+		UnaryNode: UnaryNode{Child: child},
+		Cond:      cond,
+	}
+}
+
+func (h *Having) Resolved() bool {
+	return h.Child.Resolved() && h.Cond.Resolved()
+}
+
+func (h *Having) RowIter() (sql.RowIter, error) {
+	return NewFilter(h.Cond, h.Child).RowIter()
+}
+
+func (h *Having) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := h.UnaryNode.Child.TransformUp(f)
+	return f(NewHaving(h.Cond, c))
+}
+
+func (h *Having) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := h.UnaryNode.Child.TransformExpressionsUp(f)
+	return NewHaving(h.Cond.TransformUp(f), c)
+}
@@ -0,0 +1,48 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHaving(t *testing.T) {
+	require := require.New(t)
+
+	child := mem.NewTable("test", sql.Schema{
+		{Name: "a", Type: sql.String},
+		{Name: "b", Type: sql.Integer},
+	})
+	require.NoError(child.Insert(sql.NewRow("x", int32(1))))
+	require.NoError(child.Insert(sql.NewRow("x", int32(2))))
+	require.NoError(child.Insert(sql.NewRow("y", int32(3))))
+
+	a := expression.NewGetField(0, sql.String, "a", false)
+	b := expression.NewGetField(1, sql.Integer, "b", false)
+	g := NewGroupBy(
+		[]sql.Expression{a, expression.NewCount(b)},
+		[]sql.Expression{a},
+		child,
+	)
+
+	h := NewHaving(
+		expression.NewGreaterThan(
+			expression.NewGetField(1, sql.Integer, "count(b)", false),
+			expression.NewLiteral(int32(1), sql.Integer),
+		),
+		g,
+	)
+
+	require.True(h.Resolved())
+	require.Equal(g.Schema(), h.Schema())
+
+	rows, err := sql.NodeToRows(h)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow("x", int32(2))}, rows)
+
+	require.False(NewHaving(expression.NewUnresolvedColumn("a"), g).Resolved())
+}
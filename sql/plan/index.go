@@ -0,0 +1,84 @@
+package plan
+
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// IndexLookup returns the rows of its child, a table implementing
+// sql.IndexableTable, whose value for the column of an index is in a range.
+type IndexLookup struct {
+	UnaryNode
+	Index string
+	Range sql.IndexRange
+}
+
+// NewIndexLookup creates a new IndexLookup node.
+func NewIndexLookup(index string, r sql.IndexRange, table sql.Node) *IndexLookup {
+	return &IndexLookup{UnaryNode{table}, index, r}
+}
+
+func (p *IndexLookup) RowIter() (sql.RowIter, error) {
+	t, ok := p.Child.(sql.IndexableTable)
+	if !ok {
+		return nil, fmt.Errorf("node is not an indexable table: %T", p.Child)
+	}
+
+	return t.IndexLookup(p.Index, p.Range)
+}
+
+func (p *IndexLookup) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewIndexLookup(p.Index, p.Range, c))
+}
+
+func (p *IndexLookup) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewIndexLookup(p.Index, p.Range, c)
+}
+
+// CreateIndex creates an index on its child, a table implementing
+// sql.IndexableTable.
+type CreateIndex struct {
+	UnaryNode
+	Index sql.Index
+}
+
+// NewCreateIndex creates a new CreateIndex node.
+func NewCreateIndex(index sql.Index, table sql.Node) *CreateIndex {
+	return &CreateIndex{UnaryNode{table}, index}
+}
+
+func (*CreateIndex) Schema() sql.Schema {
+	return sql.Schema{}
+}
+
+// Execute creates the index.
+func (p *CreateIndex) Execute() (sql.Result, error) {
+	t, err := findTable(p.Child)
+	if err != nil {
+		return sql.Result{}, err
+	}
+
+	it, ok := t.(sql.IndexableTable)
+	if !ok {
+		return sql.Result{}, fmt.Errorf("table %s can't be indexed", t.Name())
+	}
+
+	return sql.Result{}, it.CreateIndex(p.Index)
+}
+
+func (p *CreateIndex) RowIter() (sql.RowIter, error) {
+	return executeToRowIter(p)
+}
+
+func (p *CreateIndex) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewCreateIndex(p.Index, c))
+}
+
+func (p *CreateIndex) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewCreateIndex(p.Index, c)
+}
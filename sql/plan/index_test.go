@@ -0,0 +1,44 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateIndex_IndexLookup(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+	})
+	for i := int64(1); i <= 5; i++ {
+		require.NoError(table.Insert(sql.NewRow(i)))
+	}
+
+	index := sql.Index{Name: "idx", Column: "a", Type: sql.BTreeIndex}
+	rows, err := sql.NodeToRows(NewCreateIndex(index, NewTableAlias("alias", table)))
+	require.NoError(err)
+	require.Len(rows, 0)
+	require.Equal([]sql.Index{index}, table.Indexes())
+
+	lookup := NewIndexLookup("idx", sql.IndexRange{
+		Lower:          int64(2),
+		Upper:          int64(4),
+		LowerInclusive: true,
+	}, table)
+	require.Equal(table.Schema(), lookup.Schema())
+
+	rows, err = sql.NodeToRows(lookup)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(2)), sql.NewRow(int64(3))}, rows)
+
+	_, err = NewCreateIndex(index, table).Execute()
+	require.Error(err)
+
+	_, err = NewIndexLookup("idx", sql.NewIndexPoint(int64(1)), NewValues(nil)).RowIter()
+	require.Error(err)
+}
@@ -2,15 +2,39 @@ package plan
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"reflect"
 
 	"gopkg.in/sqle/sqle.v0/sql"
 	"gopkg.in/sqle/sqle.v0/sql/expression"
 )
 
+// InsertMode is the way an InsertInto handles rows with the same key as a
+// row already in the table.
+type InsertMode int
+
+const (
+	// InsertDefault fails when a row has a duplicate key.
+	InsertDefault InsertMode = iota
+	// InsertIgnore skips the rows with a duplicate key, as INSERT IGNORE.
+	InsertIgnore
+	// InsertReplace deletes the existing rows with a duplicate key before
+	// inserting the row, as REPLACE.
+	InsertReplace
+	// InsertOnDuplicateKeyUpdate updates the existing row with a duplicate
+	// key instead, as INSERT ... ON DUPLICATE KEY UPDATE.
+	InsertOnDuplicateKeyUpdate
+)
+
 type InsertInto struct {
 	BinaryNode
 	Columns []string
+	Mode    InsertMode
+	// OnDuplicateUpdate are the columns of the existing row to update in
+	// InsertOnDuplicateKeyUpdate mode. The expressions are evaluated against
+	// the existing row followed by the row being inserted.
+	OnDuplicateUpdate []UpdateField
 }
 
 func NewInsertInto(dst, src sql.Node, cols []string) *InsertInto {
@@ -20,88 +44,340 @@ func NewInsertInto(dst, src sql.Node, cols []string) *InsertInto {
 	}
 }
 
+// NewInsertIgnore creates a new InsertInto that skips rows with a duplicate
+// key.
+func NewInsertIgnore(dst, src sql.Node, cols []string) *InsertInto {
+	n := NewInsertInto(dst, src, cols)
+	n.Mode = InsertIgnore
+	return n
+}
+
+// NewReplaceInto creates a new InsertInto that replaces the existing rows
+// with a duplicate key.
+func NewReplaceInto(dst, src sql.Node, cols []string) *InsertInto {
+	n := NewInsertInto(dst, src, cols)
+	n.Mode = InsertReplace
+	return n
+}
+
+// NewInsertOnDuplicateKeyUpdate creates a new InsertInto that updates the
+// existing rows with a duplicate key with the given fields.
+func NewInsertOnDuplicateKeyUpdate(dst, src sql.Node, cols []string,
+	fields []UpdateField) *InsertInto {
+
+	n := NewInsertInto(dst, src, cols)
+	n.Mode = InsertOnDuplicateKeyUpdate
+	n.OnDuplicateUpdate = fields
+	return n
+}
+
+func (p *InsertInto) Resolved() bool {
+	if !p.BinaryNode.Resolved() {
+		return false
+	}
+
+	for _, f := range p.OnDuplicateUpdate {
+		if !expressionsResolved(f.Column, f.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *InsertInto) Schema() sql.Schema {
 	return sql.Schema{{
-		Name:     "updated",
+		Name:     "inserted",
 		Type:     sql.BigInteger,
 		Default:  int64(0),
 		Nullable: false,
 	}}
 }
 
-func (p *InsertInto) Execute() (int, error) {
-	insertable, ok := p.Left.(sql.Inserter)
-	if !ok {
-		return 0, errors.New("destination table does not support INSERT TO")
+// Execute inserts the rows and returns the number of rows inserted, updated
+// and skipped, and the last key generated for them, if the table generates
+// keys.
+func (p *InsertInto) Execute() (sql.Result, error) {
+	ins, err := newInserter(p)
+	if err != nil {
+		return sql.Result{}, err
 	}
 
 	dstSchema := p.Left.Schema()
-	projExprs := make([]sql.Expression, len(dstSchema))
-	for i, f := range dstSchema {
-		found := false
-		for j, col := range p.Columns {
-			if f.Name == col {
-				projExprs[i] = expression.NewGetField(j, f.Type, f.Name, f.Nullable)
-				found = true
-				break
-			}
-		}
-
-		if !found {
-			projExprs[i] = expression.NewLiteral(f.Type.Default(), f.Type)
-		}
+	indexes, width, err := p.valueIndexes(dstSchema)
+	if err != nil {
+		return ins.result, err
 	}
 
-	proj := NewProject(projExprs, p.Right)
-
-	iter, err := proj.RowIter()
+	iter, err := p.Right.RowIter()
 	if err != nil {
-		return 0, err
+		return ins.result, err
 	}
 
-	i := 0
 	for {
-		row, err := iter.Next()
+		values, err := iter.Next()
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
 			_ = iter.Close()
-			return i, err
+			return ins.result, err
 		}
 
-		if err := insertable.Insert(row); err != nil {
+		if len(values) != width {
 			_ = iter.Close()
-			return i, err
+			return ins.result, fmt.Errorf(
+				"column count doesn't match value count: %d columns and %d values",
+				width, len(values))
+		}
+
+		row := make(sql.Row, len(dstSchema))
+		for i, f := range dstSchema {
+			if indexes[i] >= 0 {
+				row[i] = values[indexes[i]]
+				continue
+			}
+
+			row[i], err = defaultValue(f)
+			if err != nil {
+				_ = iter.Close()
+				return ins.result, err
+			}
+		}
+
+		if err := convertRow(dstSchema, row); err != nil {
+			_ = iter.Close()
+			return ins.result, err
+		}
+
+		if err := ins.insert(row); err != nil {
+			_ = iter.Close()
+			return ins.result, err
+		}
+	}
+
+	return ins.result, iter.Close()
+}
+
+// valueIndexes returns, for each column of the schema of the table, the
+// index of its value in the inserted rows, or -1 if it has no value and gets
+// its default one, along with the number of values of each row. Without a
+// list of columns, rows have a value for every column, in the same order.
+func (p *InsertInto) valueIndexes(schema sql.Schema) ([]int, int, error) {
+	indexes := make([]int, len(schema))
+	if len(p.Columns) == 0 {
+		for i := range schema {
+			indexes[i] = i
+		}
+
+		return indexes, len(schema), nil
+	}
+
+	for i := range indexes {
+		indexes[i] = -1
+	}
+
+	for j, col := range p.Columns {
+		i := -1
+		for idx, c := range schema {
+			if c.Name == col {
+				i = idx
+			}
+		}
+
+		if i < 0 {
+			return nil, 0, fmt.Errorf("column not found: %s", col)
+		}
+
+		if indexes[i] >= 0 {
+			return nil, 0, fmt.Errorf("column specified twice: %s", col)
+		}
+
+		indexes[i] = j
+	}
+
+	return indexes, len(p.Columns), nil
+}
+
+// inserter inserts rows in a table handling duplicate keys according to the
+// mode of an InsertInto.
+type inserter struct {
+	p      *InsertInto
+	table  sql.Inserter
+	result sql.Result
+}
+
+func newInserter(p *InsertInto) (*inserter, error) {
+	table, ok := p.Left.(sql.Inserter)
+	if !ok {
+		return nil, errors.New("destination table does not support INSERT TO")
+	}
+
+	switch p.Mode {
+	case InsertReplace:
+		if _, ok := table.(sql.Deleter); !ok {
+			return nil, errors.New("destination table does not support REPLACE")
+		}
+	case InsertOnDuplicateKeyUpdate:
+		if _, ok := table.(sql.Updater); !ok {
+			return nil, errors.New("destination table does not support ON DUPLICATE KEY UPDATE")
+		}
+	}
+
+	return &inserter{p: p, table: table}, nil
+}
+
+func (i *inserter) insert(row sql.Row) error {
+	replaced := false
+	for {
+		err := i.insertRow(row)
+		if err == nil {
+			if replaced {
+				i.result.Updated++
+			} else {
+				i.result.Inserted++
+			}
+
+			return nil
+		}
+
+		var dup *sql.DuplicateKeyError
+		if !errors.As(err, &dup) {
+			return err
+		}
+
+		switch i.p.Mode {
+		case InsertIgnore:
+			i.result.Skipped++
+			return nil
+		case InsertReplace:
+			if err := i.table.(sql.Deleter).Delete([]sql.Row{dup.Existing}); err != nil {
+				return err
+			}
+
+			// The row is inserted again, since it may have a duplicate
+			// key with other rows too.
+			i.result.RowsAffected++
+			replaced = true
+		case InsertOnDuplicateKeyUpdate:
+			return i.update(dup.Existing, row)
+		default:
+			return err
+		}
+	}
+}
+
+func (i *inserter) insertRow(row sql.Row) error {
+	if ki, ok := i.table.(sql.KeyInserter); ok {
+		id, err := ki.InsertWithKey(row)
+		if err != nil {
+			return err
+		}
+
+		i.result.LastInsertID = id
+	} else if err := i.table.Insert(row); err != nil {
+		return err
+	}
+
+	i.result.RowsAffected++
+	return nil
+}
+
+func (i *inserter) update(existing, row sql.Row) error {
+	schema := i.p.Left.Schema()
+	evalRow := append(existing.Copy(), row...)
+	newRow := existing.Copy()
+	for _, f := range i.p.OnDuplicateUpdate {
+		gf, ok := f.Column.(*expression.GetField)
+		if !ok || gf.Index() >= len(schema) {
+			return fmt.Errorf("can't update expression %s", f.Column.Name())
+		}
+
+		v, err := f.Value.Eval(evalRow)
+		if err != nil {
+			return err
+		}
+
+		newRow[gf.Index()] = v
+	}
+
+	if err := convertRow(schema, newRow); err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(existing, newRow) {
+		i.result.Skipped++
+		return nil
+	}
+
+	err := i.table.(sql.Updater).Update([]sql.Row{existing}, []sql.Row{newRow})
+	if err != nil {
+		return err
+	}
+
+	i.result.RowsAffected += 2
+	i.result.Updated++
+	return nil
+}
+
+// defaultValue returns the value of a column missing in an INSERT: its
+// default value if it has one, or NULL if it's nullable. It fails if the
+// column is not nullable and has no default value.
+func defaultValue(c *sql.Column) (interface{}, error) {
+	if c.Default == nil && !c.Nullable {
+		return nil, fmt.Errorf("%w: column %s has no default value",
+			sql.ErrNotNullable, c.Name)
+	}
+
+	return c.Default, nil
+}
+
+// convertRow converts in place the values of the row to the types of the
+// columns of the schema.
+func convertRow(schema sql.Schema, row sql.Row) error {
+	for i, c := range schema {
+		if row[i] == nil {
+			if !c.Nullable {
+				return fmt.Errorf("%w in column %s", sql.ErrNotNullable, c.Name)
+			}
+
+			continue
+		}
+
+		v, err := c.Type.Convert(row[i])
+		if err != nil {
+			return fmt.Errorf("invalid value for column %s: %s", c.Name, err)
 		}
 
-		i++
+		row[i] = v
 	}
 
-	return i, nil
+	return nil
 }
 
 func (p *InsertInto) RowIter() (sql.RowIter, error) {
-	n, err := p.Execute()
+	result, err := p.Execute()
 	if err != nil {
 		return nil, err
 	}
 
-	return sql.RowsToRowIter(sql.NewRow(int64(n))), nil
+	return sql.RowsToRowIter(sql.NewRow(result.RowsAffected)), nil
 }
 
 func (p *InsertInto) TransformUp(f func(sql.Node) sql.Node) sql.Node {
-	ln := p.BinaryNode.Left.TransformUp(f)
-	rn := p.BinaryNode.Right.TransformUp(f)
+	n := *p
+	n.Left = p.BinaryNode.Left.TransformUp(f)
+	n.Right = p.BinaryNode.Right.TransformUp(f)
 
-	n := NewInsertInto(ln, rn, p.Columns)
-	return f(n)
+	return f(&n)
 }
 
 func (p *InsertInto) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
-	ln := p.BinaryNode.Left.TransformExpressionsUp(f)
-	rn := p.BinaryNode.Right.TransformExpressionsUp(f)
+	n := *p
+	n.Left = p.BinaryNode.Left.TransformExpressionsUp(f)
+	n.Right = p.BinaryNode.Right.TransformExpressionsUp(f)
+	n.OnDuplicateUpdate = transformUpdateFields(f, p.OnDuplicateUpdate)
 
-	return NewInsertInto(ln, rn, p.Columns)
+	return &n
 }
@@ -0,0 +1,250 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertInto(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger, Default: int64(0)},
+		{Name: "b", Type: sql.String},
+	})
+
+	insert := NewInsertInto(table, NewValues([][]sql.Expression{
+		{expression.NewLiteral("x", sql.String)},
+		{expression.NewLiteral("y", sql.String)},
+	}), []string{"b"})
+
+	result, err := insert.Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 2, Inserted: 2}, result)
+
+	rows, err := sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		sql.NewRow(int64(0), "x"),
+		sql.NewRow(int64(0), "y"),
+	}, rows)
+
+	rows, err = sql.NodeToRows(insert)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(2))}, rows)
+}
+
+func TestInsertInto_WithoutColumns(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+		{Name: "b", Type: sql.String},
+	})
+
+	insert := NewInsertInto(table, NewValues([][]sql.Expression{{
+		expression.NewLiteral(int64(1), sql.BigInteger),
+		expression.NewLiteral("x", sql.String),
+	}}), nil)
+
+	_, err := insert.Execute()
+	require.NoError(err)
+
+	rows, err := sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(1), "x")}, rows)
+
+	insert = NewInsertInto(table, NewValues([][]sql.Expression{{
+		expression.NewLiteral(int64(2), sql.BigInteger),
+	}}), nil)
+
+	_, err = insert.Execute()
+	require.Error(err)
+
+	insert = NewInsertInto(table, NewValues([][]sql.Expression{{
+		expression.NewLiteral(int64(2), sql.BigInteger),
+	}}), []string{"c"})
+
+	_, err = insert.Execute()
+	require.Error(err)
+}
+
+func TestInsertInto_DefaultsAndConversion(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.Float},
+		{Name: "b", Type: sql.String, Default: "def"},
+		{Name: "c", Type: sql.String, Nullable: true},
+		{Name: "d", Type: sql.Integer, Nullable: true, Default: int32(7)},
+	})
+
+	insert := NewInsertInto(table, NewValues([][]sql.Expression{
+		{expression.NewLiteral(int64(1), sql.BigInteger)},
+	}), []string{"a"})
+
+	_, err := insert.Execute()
+	require.NoError(err)
+
+	rows, err := sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(float64(1), "def", nil, int32(7))}, rows)
+
+	insert = NewInsertInto(table, NewValues([][]sql.Expression{{
+		expression.NewLiteral(float64(2), sql.Float),
+		expression.NewLiteral(nil, sql.Null),
+		expression.NewLiteral("x", sql.String),
+	}}), []string{"a", "d", "b"})
+
+	_, err = insert.Execute()
+	require.NoError(err)
+
+	insert = NewInsertInto(table, NewValues([][]sql.Expression{
+		{expression.NewLiteral("x", sql.String)},
+	}), []string{"b"})
+
+	_, err = insert.Execute()
+	require.True(errors.Is(err, sql.ErrNotNullable))
+	require.Contains(err.Error(), "column a has no default value")
+
+	insert = NewInsertInto(table, NewValues([][]sql.Expression{
+		{expression.NewLiteral(float64(1), sql.Float), expression.NewLiteral(nil, sql.Null)},
+	}), []string{"a", "b"})
+
+	_, err = insert.Execute()
+	require.True(errors.Is(err, sql.ErrNotNullable))
+	require.Contains(err.Error(), "column b")
+
+	insert = NewInsertInto(table, NewValues([][]sql.Expression{
+		{expression.NewLiteral("foo", sql.String)},
+	}), []string{"a"})
+
+	_, err = insert.Execute()
+	require.Error(err)
+	require.Contains(err.Error(), "column a")
+
+	rows, err = sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		sql.NewRow(float64(1), "def", nil, int32(7)),
+		sql.NewRow(float64(2), "x", nil, nil),
+	}, rows)
+}
+
+type keyTable struct {
+	*mem.Table
+	lastKey int64
+}
+
+func (t *keyTable) InsertWithKey(row sql.Row) (int64, error) {
+	if err := t.Insert(row); err != nil {
+		return 0, err
+	}
+
+	t.lastKey++
+	return t.lastKey, nil
+}
+
+func TestInsertInto_LastInsertID(t *testing.T) {
+	require := require.New(t)
+
+	table := &keyTable{Table: mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.String},
+	})}
+
+	insert := NewInsertInto(table, NewValues([][]sql.Expression{
+		{expression.NewLiteral("x", sql.String)},
+		{expression.NewLiteral("y", sql.String)},
+		{expression.NewLiteral("z", sql.String)},
+	}), []string{"a"})
+
+	result, err := insert.Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 3, LastInsertID: 3, Inserted: 3}, result)
+}
+
+// uniqueTable is a table whose first column is a unique key.
+type uniqueTable struct {
+	*mem.Table
+}
+
+func (t *uniqueTable) Insert(row sql.Row) error {
+	if existing := t.find(row[0]); existing != nil {
+		return &sql.DuplicateKeyError{Key: "PRIMARY", Existing: existing}
+	}
+
+	return t.Table.Insert(row)
+}
+
+func (t *uniqueTable) find(key interface{}) sql.Row {
+	rows, _ := sql.NodeToRows(t.Table)
+	for _, r := range rows {
+		if r[0] == key {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func TestInsertInto_DuplicateKeys(t *testing.T) {
+	require := require.New(t)
+
+	newTable := func() *uniqueTable {
+		table := &uniqueTable{mem.NewTable("t", sql.Schema{
+			{Name: "a", Type: sql.BigInteger},
+			{Name: "b", Type: sql.String},
+		})}
+		require.NoError(table.Insert(sql.NewRow(int64(1), "x")))
+		require.NoError(table.Insert(sql.NewRow(int64(2), "y")))
+		return table
+	}
+
+	values := NewValues([][]sql.Expression{
+		{expression.NewLiteral(int64(2), sql.BigInteger), expression.NewLiteral("z", sql.String)},
+		{expression.NewLiteral(int64(3), sql.BigInteger), expression.NewLiteral("z", sql.String)},
+		{expression.NewLiteral(int64(1), sql.BigInteger), expression.NewLiteral("x", sql.String)},
+	})
+	cols := []string{"a", "b"}
+
+	table := newTable()
+	_, err := NewInsertInto(table, values, cols).Execute()
+	var dup *sql.DuplicateKeyError
+	require.True(errors.As(err, &dup))
+	require.Equal(sql.NewRow(int64(2), "y"), dup.Existing)
+
+	table = newTable()
+	result, err := NewInsertIgnore(table, values, cols).Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 1, Inserted: 1, Skipped: 2}, result)
+	requireRows(t, table, sql.NewRow(int64(1), "x"), sql.NewRow(int64(2), "y"), sql.NewRow(int64(3), "z"))
+
+	table = newTable()
+	result, err = NewReplaceInto(table, values, cols).Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 5, Inserted: 1, Updated: 2}, result)
+	requireRows(t, table, sql.NewRow(int64(2), "z"), sql.NewRow(int64(3), "z"), sql.NewRow(int64(1), "x"))
+
+	// b = VALUES(b) updates the row with a = 2, while the row with a = 1
+	// already has the inserted value.
+	table = newTable()
+	result, err = NewInsertOnDuplicateKeyUpdate(table, values, cols, []UpdateField{{
+		Column: expression.NewGetField(1, sql.String, "b", false),
+		Value:  expression.NewGetField(3, sql.String, "b", false),
+	}}).Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 3, Inserted: 1, Updated: 1, Skipped: 1}, result)
+	requireRows(t, table, sql.NewRow(int64(1), "x"), sql.NewRow(int64(2), "z"), sql.NewRow(int64(3), "z"))
+}
+
+func requireRows(t *testing.T, n sql.Node, expected ...sql.Row) {
+	rows, err := sql.NodeToRows(n)
+	require.NoError(t, err)
+	require.Equal(t, expected, rows)
+}
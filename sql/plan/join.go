@@ -0,0 +1,342 @@
+package plan
+
+import (
+	"io"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// JoinType is the kind of a join.
+type JoinType byte
+
+const (
+	// JoinTypeInner only returns rows matching the condition.
+	JoinTypeInner JoinType = iota
+	// JoinTypeLeft also returns rows of the left side without a match.
+	JoinTypeLeft
+	// JoinTypeRight also returns rows of the right side without a match.
+	JoinTypeRight
+	// JoinTypeFullOuter also returns rows of both sides without a match.
+	JoinTypeFullOuter
+)
+
+// joinNode contains everything shared by the joins with a condition. The
+// condition may be given either as an expression or as a list of columns
+// with the same name on both sides (USING), which is turned into an
+// expression once both sides are resolved.
+type joinNode struct {
+	BinaryNode
+	Cond         sql.Expression
+	UsingColumns []string
+	joinType     JoinType
+}
+
+func (j *joinNode) Resolved() bool {
+	return j.Left.Resolved() && j.Right.Resolved() &&
+		j.Cond != nil && j.Cond.Resolved()
+}
+
+func (j *joinNode) Schema() sql.Schema {
+	left := j.Left.Schema()
+	right := j.Right.Schema()
+
+	if j.joinType == JoinTypeRight || j.joinType == JoinTypeFullOuter {
+		left = nullableSchema(left)
+	}
+
+	if j.joinType == JoinTypeLeft || j.joinType == JoinTypeFullOuter {
+		right = nullableSchema(right)
+	}
+
+	return append(append(sql.Schema{}, left...), right...)
+}
+
+func (j *joinNode) RowIter() (sql.RowIter, error) {
+	li, err := j.Left.RowIter()
+	if err != nil {
+		return nil, err
+	}
+
+	ri, err := j.Right.RowIter()
+	if err != nil {
+		_ = li.Close()
+		return nil, err
+	}
+
+	return &joinIter{
+		li:         li,
+		ri:         ri,
+		cond:       j.Cond,
+		joinType:   j.joinType,
+		leftWidth:  len(j.Left.Schema()),
+		rightWidth: len(j.Right.Schema()),
+	}, nil
+}
+
+func nullableSchema(s sql.Schema) sql.Schema {
+	result := make(sql.Schema, len(s))
+	for i, c := range s {
+		nc := *c
+		nc.Nullable = true
+		result[i] = &nc
+	}
+
+	return result
+}
+
+// InnerJoin returns the rows of both sides that match the given condition.
+type InnerJoin struct {
+	joinNode
+}
+
+// NewInnerJoin creates a new InnerJoin with the given condition.
+func NewInnerJoin(left, right sql.Node, cond sql.Expression) *InnerJoin {
+	return &InnerJoin{newJoinNode(left, right, cond, nil, JoinTypeInner)}
+}
+
+// NewInnerJoinUsing creates a new InnerJoin on the given columns.
+func NewInnerJoinUsing(left, right sql.Node, cols []string) *InnerJoin {
+	return &InnerJoin{newJoinNode(left, right, nil, cols, JoinTypeInner)}
+}
+
+func (j *InnerJoin) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	ln := j.Left.TransformUp(f)
+	rn := j.Right.TransformUp(f)
+
+	return f(&InnerJoin{j.withChildren(ln, rn)})
+}
+
+func (j *InnerJoin) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	return &InnerJoin{j.transformExpressionsUp(f)}
+}
+
+// LeftJoin returns the rows of both sides that match the given condition,
+// plus the rows of the left side that do not match any row of the right side,
+// padded with NULLs.
+type LeftJoin struct {
+	joinNode
+}
+
+// NewLeftJoin creates a new LeftJoin with the given condition.
+func NewLeftJoin(left, right sql.Node, cond sql.Expression) *LeftJoin {
+	return &LeftJoin{newJoinNode(left, right, cond, nil, JoinTypeLeft)}
+}
+
+// NewLeftJoinUsing creates a new LeftJoin on the given columns.
+func NewLeftJoinUsing(left, right sql.Node, cols []string) *LeftJoin {
+	return &LeftJoin{newJoinNode(left, right, nil, cols, JoinTypeLeft)}
+}
+
+func (j *LeftJoin) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	ln := j.Left.TransformUp(f)
+	rn := j.Right.TransformUp(f)
+
+	return f(&LeftJoin{j.withChildren(ln, rn)})
+}
+
+func (j *LeftJoin) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	return &LeftJoin{j.transformExpressionsUp(f)}
+}
+
+// RightJoin returns the rows of both sides that match the given condition,
+// plus the rows of the right side that do not match any row of the left side,
+// padded with NULLs.
+type RightJoin struct {
+	joinNode
+}
+
+// NewRightJoin creates a new RightJoin with the given condition.
+func NewRightJoin(left, right sql.Node, cond sql.Expression) *RightJoin {
+	return &RightJoin{newJoinNode(left, right, cond, nil, JoinTypeRight)}
+}
+
+// NewRightJoinUsing creates a new RightJoin on the given columns.
+func NewRightJoinUsing(left, right sql.Node, cols []string) *RightJoin {
+	return &RightJoin{newJoinNode(left, right, nil, cols, JoinTypeRight)}
+}
+
+func (j *RightJoin) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	ln := j.Left.TransformUp(f)
+	rn := j.Right.TransformUp(f)
+
+	return f(&RightJoin{j.withChildren(ln, rn)})
+}
+
+func (j *RightJoin) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	return &RightJoin{j.transformExpressionsUp(f)}
+}
+
+// FullOuterJoin returns the rows of both sides that match the given
+// condition, plus the rows of any side that do not match any row of the other
+// one, padded with NULLs. The parser does not support FULL OUTER JOIN, so it
+// can only be built directly, and only with an ON condition.
+type FullOuterJoin struct {
+	joinNode
+}
+
+// NewFullOuterJoin creates a new FullOuterJoin with the given condition.
+func NewFullOuterJoin(left, right sql.Node, cond sql.Expression) *FullOuterJoin {
+	return &FullOuterJoin{newJoinNode(left, right, cond, nil, JoinTypeFullOuter)}
+}
+
+func (j *FullOuterJoin) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	ln := j.Left.TransformUp(f)
+	rn := j.Right.TransformUp(f)
+
+	return f(&FullOuterJoin{j.withChildren(ln, rn)})
+}
+
+func (j *FullOuterJoin) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	return &FullOuterJoin{j.transformExpressionsUp(f)}
+}
+
+func newJoinNode(left, right sql.Node, cond sql.Expression, using []string,
+	joinType JoinType) joinNode {
+
+	return joinNode{
+		BinaryNode:   BinaryNode{Left: left, Right: right},
+		Cond:         cond,
+		UsingColumns: using,
+		joinType:     joinType,
+	}
+}
+
+func (j *joinNode) withChildren(left, right sql.Node) joinNode {
+	return newJoinNode(left, right, j.Cond, j.UsingColumns, j.joinType)
+}
+
+func (j *joinNode) transformExpressionsUp(
+	f func(sql.Expression) sql.Expression) joinNode {
+
+	ln := j.Left.TransformExpressionsUp(f)
+	rn := j.Right.TransformExpressionsUp(f)
+
+	var cond sql.Expression
+	if j.Cond != nil {
+		cond = j.Cond.TransformUp(f)
+	}
+
+	return newJoinNode(ln, rn, cond, j.UsingColumns, j.joinType)
+}
+
+// JoinType returns the kind of the join.
+func (j *joinNode) JoinType() JoinType {
+	return j.joinType
+}
+
+type joinIter struct {
+	li         sql.RowIter
+	ri         sql.RowIter
+	cond       sql.Expression
+	joinType   JoinType
+	leftWidth  int
+	rightWidth int
+
+	rightRows    []sql.Row
+	rightMatched []bool
+	loaded       bool
+
+	leftRow      sql.Row
+	leftMatched  bool
+	index        int
+	leftDone     bool
+	unmatchedIdx int
+}
+
+func (i *joinIter) Next() (sql.Row, error) {
+	if !i.loaded {
+		if err := i.loadRight(); err != nil {
+			return nil, err
+		}
+	}
+
+	for !i.leftDone {
+		if i.leftRow == nil {
+			lr, err := i.li.Next()
+			if err == io.EOF {
+				i.leftDone = true
+				break
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			i.leftRow = lr
+			i.leftMatched = false
+			i.index = 0
+		}
+
+		for i.index < len(i.rightRows) {
+			idx := i.index
+			i.index++
+
+			row := joinRows(i.leftRow, i.rightRows[idx])
+			v, err := i.cond.Eval(row)
+			if err != nil {
+				return nil, err
+			}
+
+			if v == true {
+				i.leftMatched = true
+				i.rightMatched[idx] = true
+				return row, nil
+			}
+		}
+
+		lr := i.leftRow
+		i.leftRow = nil
+		if !i.leftMatched &&
+			(i.joinType == JoinTypeLeft || i.joinType == JoinTypeFullOuter) {
+			return joinRows(lr, make(sql.Row, i.rightWidth)), nil
+		}
+	}
+
+	if i.joinType == JoinTypeRight || i.joinType == JoinTypeFullOuter {
+		for i.unmatchedIdx < len(i.rightRows) {
+			idx := i.unmatchedIdx
+			i.unmatchedIdx++
+
+			if !i.rightMatched[idx] {
+				return joinRows(make(sql.Row, i.leftWidth), i.rightRows[idx]), nil
+			}
+		}
+	}
+
+	return nil, io.EOF
+}
+
+func (i *joinIter) loadRight() error {
+	for {
+		row, err := i.ri.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		i.rightRows = append(i.rightRows, row)
+	}
+
+	i.rightMatched = make([]bool, len(i.rightRows))
+	i.loaded = true
+	return nil
+}
+
+func (i *joinIter) Close() error {
+	i.rightRows = nil
+	if err := i.li.Close(); err != nil {
+		_ = i.ri.Close()
+		return err
+	}
+
+	return i.ri.Close()
+}
+
+func joinRows(left, right sql.Row) sql.Row {
+	row := make(sql.Row, 0, len(left)+len(right))
+	row = append(row, left...)
+	return append(row, right...)
+}
@@ -0,0 +1,135 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoins(t *testing.T) {
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "id", Type: sql.BigInteger},
+		{Name: "name", Type: sql.String},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "lid", Type: sql.BigInteger},
+		{Name: "value", Type: sql.String},
+	})
+
+	require.NoError(t, left.Insert(sql.NewRow(int64(1), "a")))
+	require.NoError(t, left.Insert(sql.NewRow(int64(2), "b")))
+	require.NoError(t, right.Insert(sql.NewRow(int64(1), "x")))
+	require.NoError(t, right.Insert(sql.NewRow(int64(1), "y")))
+	require.NoError(t, right.Insert(sql.NewRow(int64(3), "z")))
+
+	cond := expression.NewEquals(
+		expression.NewGetField(0, sql.BigInteger, "id", false),
+		expression.NewGetField(2, sql.BigInteger, "lid", false),
+	)
+
+	var testCases = []struct {
+		name     string
+		node     sql.Node
+		expected []sql.Row
+	}{
+		{
+			"inner join",
+			NewInnerJoin(left, right, cond),
+			[]sql.Row{
+				{int64(1), "a", int64(1), "x"},
+				{int64(1), "a", int64(1), "y"},
+			},
+		},
+		{
+			"left join",
+			NewLeftJoin(left, right, cond),
+			[]sql.Row{
+				{int64(1), "a", int64(1), "x"},
+				{int64(1), "a", int64(1), "y"},
+				{int64(2), "b", nil, nil},
+			},
+		},
+		{
+			"right join",
+			NewRightJoin(left, right, cond),
+			[]sql.Row{
+				{int64(1), "a", int64(1), "x"},
+				{int64(1), "a", int64(1), "y"},
+				{nil, nil, int64(3), "z"},
+			},
+		},
+		{
+			"full outer join",
+			NewFullOuterJoin(left, right, cond),
+			[]sql.Row{
+				{int64(1), "a", int64(1), "x"},
+				{int64(1), "a", int64(1), "y"},
+				{int64(2), "b", nil, nil},
+				{nil, nil, int64(3), "z"},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			require.True(tt.node.Resolved())
+
+			rows, err := sql.NodeToRows(tt.node)
+			require.NoError(err)
+			require.Equal(tt.expected, rows)
+		})
+	}
+}
+
+func TestJoin_Schema(t *testing.T) {
+	require := require.New(t)
+
+	left := mem.NewTable("left", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+	})
+	right := mem.NewTable("right", sql.Schema{
+		{Name: "b", Type: sql.String},
+	})
+	cond := expression.NewLiteral(true, sql.Boolean)
+
+	require.Equal(sql.Schema{
+		{Name: "a", Type: sql.BigInteger, Source: "left"},
+		{Name: "b", Type: sql.String, Source: "right"},
+	}, NewInnerJoin(left, right, cond).Schema())
+
+	require.Equal(sql.Schema{
+		{Name: "a", Type: sql.BigInteger, Source: "left"},
+		{Name: "b", Type: sql.String, Nullable: true, Source: "right"},
+	}, NewLeftJoin(left, right, cond).Schema())
+
+	require.Equal(sql.Schema{
+		{Name: "a", Type: sql.BigInteger, Nullable: true, Source: "left"},
+		{Name: "b", Type: sql.String, Source: "right"},
+	}, NewRightJoin(left, right, cond).Schema())
+
+	require.Equal(sql.Schema{
+		{Name: "a", Type: sql.BigInteger, Nullable: true, Source: "left"},
+		{Name: "b", Type: sql.String, Nullable: true, Source: "right"},
+	}, NewFullOuterJoin(left, right, cond).Schema())
+
+	// original schemas must not be modified
+	require.False(left.Schema()[0].Nullable)
+	require.False(right.Schema()[0].Nullable)
+}
+
+func TestJoin_Using(t *testing.T) {
+	require := require.New(t)
+
+	left := mem.NewTable("left", sql.Schema{{Name: "a", Type: sql.BigInteger}})
+	right := mem.NewTable("right", sql.Schema{{Name: "a", Type: sql.BigInteger}})
+
+	j := NewLeftJoinUsing(left, right, []string{"a"})
+	require.False(j.Resolved())
+	require.Equal([]string{"a"}, j.UsingColumns)
+	require.Equal(JoinTypeLeft, j.JoinType())
+}
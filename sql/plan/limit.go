@@ -1,57 +1,98 @@
 package plan
 
 import (
+	"fmt"
 	"io"
 
 	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
 )
 
 type Limit struct {
 	UnaryNode
-	size int64
+	size sql.Expression
 }
 
 func NewLimit(size int64, child sql.Node) *Limit {
+	return NewLimitExpression(expression.NewLiteral(size, sql.BigInteger), child)
+}
+
+// NewLimitExpression creates a new Limit whose size is given by an
+// expression, such as a placeholder, evaluated when the rows are requested.
+func NewLimitExpression(size sql.Expression, child sql.Node) *Limit {
 	return &Limit{
 		UnaryNode: UnaryNode{Child: child},
 		size:      size,
 	}
 }
 
+// RowCount returns the expression of the maximum number of rows.
+func (l *Limit) RowCount() sql.Expression {
+	return l.size
+}
+
 func (p *Limit) Resolved() bool {
-	return p.UnaryNode.Child.Resolved()
+	return p.UnaryNode.Child.Resolved() && p.size.Resolved()
 }
 
 func (l *Limit) RowIter() (sql.RowIter, error) {
+	size, err := evalRowCount(l.size)
+	if err != nil {
+		return nil, err
+	}
+
 	li, err := l.Child.RowIter()
 	if err != nil {
 		return nil, err
 	}
-	return &limitIter{l, 0, li}, nil
+	return &limitIter{size, 0, li}, nil
 }
 
 func (l *Limit) TransformUp(f func(sql.Node) sql.Node) sql.Node {
 	c := l.UnaryNode.Child.TransformUp(f)
-	n := NewLimit(l.size, c)
+	n := NewLimitExpression(l.size, c)
 
 	return f(n)
 }
 
 func (l *Limit) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
 	c := l.UnaryNode.Child.TransformExpressionsUp(f)
-	n := NewLimit(l.size, c)
+	n := NewLimitExpression(l.size.TransformUp(f), c)
 
 	return n
 }
 
+// evalRowCount evaluates the number of rows of a Limit or an Offset.
+func evalRowCount(e sql.Expression) (int64, error) {
+	v, err := e.Eval(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if v == nil {
+		return 0, fmt.Errorf("invalid number of rows: NULL")
+	}
+
+	n, err := sql.BigInteger.Convert(v)
+	if err != nil {
+		return 0, err
+	}
+
+	if n.(int64) < 0 {
+		return 0, fmt.Errorf("invalid number of rows: %d", n)
+	}
+
+	return n.(int64), nil
+}
+
 type limitIter struct {
-	l          *Limit
+	size       int64
 	currentPos int64
 	childIter  sql.RowIter
 }
 
 func (li *limitIter) Next() (sql.Row, error) {
-	if li.currentPos >= li.l.size {
+	if li.currentPos >= li.size {
 		return nil, io.EOF
 	}
 	childRow, err := li.childIter.Next()
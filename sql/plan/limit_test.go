@@ -8,6 +8,7 @@ import (
 
 	"gopkg.in/sqle/sqle.v0/mem"
 	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -65,6 +66,34 @@ func TestLimitGreaterThanTotal(t *testing.T) {
 	testLimitOverflow(t, iterator, testingLimit, testingTableSize)
 }
 
+func TestLimitExpression(t *testing.T) {
+	assert := assert.New(t)
+	table, _ := getTestingTable()
+
+	var limit sql.Node = NewLimitExpression(expression.NewPlaceholder("v1"), table)
+	_, err := limit.RowIter()
+	assert.Equal(expression.ErrUnboundPlaceholder, err)
+
+	limit = limit.TransformExpressionsUp(func(e sql.Expression) sql.Expression {
+		if _, ok := e.(*expression.Placeholder); ok {
+			return expression.NewLiteral(int64(1), sql.BigInteger)
+		}
+		return e
+	})
+
+	rows, err := sql.NodeToRows(limit)
+	assert.Nil(err)
+	assert.Len(rows, 1)
+
+	limit = NewLimitExpression(expression.NewLiteral(int64(-1), sql.BigInteger), table)
+	_, err = limit.RowIter()
+	assert.Error(err)
+
+	limit = NewLimitExpression(expression.NewLiteral(nil, sql.Null), table)
+	_, err = limit.RowIter()
+	assert.Error(err)
+}
+
 func testLimitOverflow(t *testing.T, iter sql.RowIter, limit int, dataSize int) {
 	assert := assert.New(t)
 	for i := 0; i < limit+1; i++ {
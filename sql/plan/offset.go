@@ -0,0 +1,81 @@
+package plan
+
+import (
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+)
+
+// Offset skips the first n rows of its child.
+type Offset struct {
+	UnaryNode
+	n sql.Expression
+}
+
+// NewOffset creates a new Offset node that skips n rows.
+func NewOffset(n int64, child sql.Node) *Offset {
+	return NewOffsetExpression(expression.NewLiteral(n, sql.BigInteger), child)
+}
+
+// NewOffsetExpression creates a new Offset whose number of rows to skip is
+// given by an expression, such as a placeholder, evaluated when the rows are
+// requested.
+func NewOffsetExpression(n sql.Expression, child sql.Node) *Offset {
+	return &Offset{
+		UnaryNode: UnaryNode{Child: child},
+		n:         n,
+	}
+}
+
+// RowCount returns the expression of the number of rows to skip.
+func (o *Offset) RowCount() sql.Expression {
+	return o.n
+}
+
+func (o *Offset) Resolved() bool {
+	return o.Child.Resolved() && o.n.Resolved()
+}
+
+func (o *Offset) RowIter() (sql.RowIter, error) {
+	n, err := evalRowCount(o.n)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := o.Child.RowIter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &offsetIter{n, it}, nil
+}
+
+func (o *Offset) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := o.UnaryNode.Child.TransformUp(f)
+	return f(NewOffsetExpression(o.n, c))
+}
+
+func (o *Offset) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := o.UnaryNode.Child.TransformExpressionsUp(f)
+	return NewOffsetExpression(o.n.TransformUp(f), c)
+}
+
+type offsetIter struct {
+	skip      int64
+	childIter sql.RowIter
+}
+
+func (i *offsetIter) Next() (sql.Row, error) {
+	for i.skip > 0 {
+		if _, err := i.childIter.Next(); err != nil {
+			return nil, err
+		}
+
+		i.skip--
+	}
+
+	return i.childIter.Next()
+}
+
+func (i *offsetIter) Close() error {
+	return i.childIter.Close()
+}
@@ -0,0 +1,45 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffset(t *testing.T) {
+	require := require.New(t)
+
+	table, size := getTestingTable()
+
+	o := NewOffset(1, table)
+	require.Equal(table.Schema(), o.Schema())
+	require.True(o.Resolved())
+
+	rows, err := sql.NodeToRows(o)
+	require.NoError(err)
+	require.Len(rows, size-1)
+
+	expected, err := sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal(expected[1:], rows)
+
+	rows, err = sql.NodeToRows(NewOffset(int64(size+1), table))
+	require.NoError(err)
+	require.Len(rows, 0)
+
+	rows, err = sql.NodeToRows(NewLimit(1, NewOffset(1, table)))
+	require.NoError(err)
+	require.Equal(expected[1:2], rows)
+
+	o = NewOffsetExpression(expression.NewLiteral(int64(2), sql.BigInteger), table)
+	rows, err = sql.NodeToRows(o)
+	require.NoError(err)
+	require.Equal(expected[2:], rows)
+
+	o = NewOffsetExpression(expression.NewPlaceholder("v1"), table)
+	_, err = o.RowIter()
+	require.Equal(expression.ErrUnboundPlaceholder, err)
+}
@@ -2,6 +2,7 @@ package plan
 
 import (
 	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
 )
 
 type Project struct {
@@ -24,6 +25,11 @@ func (p *Project) Schema() sql.Schema {
 			Type:     e.Type(),
 			Nullable: e.IsNullable(),
 		}
+
+		if gf, ok := e.(*expression.GetField); ok {
+			f.Source = gf.Table()
+		}
+
 		s = append(s, f)
 	}
 	return s
@@ -67,17 +73,21 @@ func (i *iter) Next() (sql.Row, error) {
 	if err != nil {
 		return nil, err
 	}
-	return filterRow(i.p.Expressions, childRow), nil
+	return filterRow(i.p.Expressions, childRow)
 }
 
 func (i *iter) Close() error {
 	return i.childIter.Close()
 }
 
-func filterRow(expressions []sql.Expression, row sql.Row) sql.Row {
+func filterRow(expressions []sql.Expression, row sql.Row) (sql.Row, error) {
 	fields := []interface{}{}
 	for _, expr := range expressions {
-		fields = append(fields, expr.Eval(row))
+		f, err := expr.Eval(row)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
 	}
-	return sql.NewRow(fields...)
+	return sql.NewRow(fields...), nil
 }
@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"fmt"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// PushdownTable returns the rows of its child, a table implementing
+// sql.FilteredTable or sql.ProjectedTable, letting the table filter them and
+// read only the needed columns.
+type PushdownTable struct {
+	UnaryNode
+	// Columns are the names of the needed columns, or nil if all of them are.
+	Columns []string
+	// Filters are filters handled by the table. Their fields refer to the
+	// schema of the table.
+	Filters []sql.Expression
+}
+
+// NewPushdownTable creates a new PushdownTable node.
+func NewPushdownTable(columns []string, filters []sql.Expression, table sql.Node) *PushdownTable {
+	return &PushdownTable{UnaryNode{table}, columns, filters}
+}
+
+func (p *PushdownTable) Resolved() bool {
+	return p.Child.Resolved() && expressionsResolved(p.Filters...)
+}
+
+func (p *PushdownTable) RowIter() (sql.RowIter, error) {
+	if len(p.Filters) > 0 {
+		t, ok := p.Child.(sql.FilteredTable)
+		if !ok {
+			return nil, fmt.Errorf("node is not a filtered table: %T", p.Child)
+		}
+
+		return t.WithFilters(p.Columns, p.Filters)
+	}
+
+	if p.Columns != nil {
+		t, ok := p.Child.(sql.ProjectedTable)
+		if !ok {
+			return nil, fmt.Errorf("node is not a projected table: %T", p.Child)
+		}
+
+		return t.WithProject(p.Columns)
+	}
+
+	return p.Child.RowIter()
+}
+
+func (p *PushdownTable) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewPushdownTable(p.Columns, p.Filters, c))
+}
+
+func (p *PushdownTable) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	return NewPushdownTable(p.Columns, transformExpressionsUp(f, p.Filters), c)
+}
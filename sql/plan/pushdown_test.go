@@ -0,0 +1,70 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushdownTable(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+		{Name: "b", Type: sql.String},
+	})
+	require.NoError(table.Insert(sql.NewRow(int64(1), "x")))
+	require.NoError(table.Insert(sql.NewRow(int64(2), "y")))
+
+	filters := []sql.Expression{
+		expression.NewEquals(
+			expression.NewGetField(1, sql.String, "b", false),
+			expression.NewLiteral("y", sql.String),
+		),
+	}
+
+	testCases := []struct {
+		name     string
+		node     *PushdownTable
+		expected []sql.Row
+	}{
+		{
+			"nothing",
+			NewPushdownTable(nil, nil, table),
+			[]sql.Row{sql.NewRow(int64(1), "x"), sql.NewRow(int64(2), "y")},
+		},
+		{
+			"columns",
+			NewPushdownTable([]string{"a"}, nil, table),
+			[]sql.Row{sql.NewRow(int64(1), nil), sql.NewRow(int64(2), nil)},
+		},
+		{
+			"filters",
+			NewPushdownTable(nil, filters, table),
+			[]sql.Row{sql.NewRow(int64(2), "y")},
+		},
+		{
+			"columns and filters",
+			NewPushdownTable([]string{}, filters, table),
+			[]sql.Row{sql.NewRow(nil, nil)},
+		},
+	}
+
+	for _, tt := range testCases {
+		require.Equal(table.Schema(), tt.node.Schema(), tt.name)
+
+		rows, err := sql.NodeToRows(tt.node)
+		require.NoError(err, tt.name)
+		require.Equal(tt.expected, rows, tt.name)
+	}
+
+	_, err := NewPushdownTable(nil, filters, NewValues(nil)).RowIter()
+	require.Error(err)
+
+	_, err = NewPushdownTable([]string{"a"}, nil, NewValues(nil)).RowIter()
+	require.Error(err)
+}
@@ -1,15 +1,21 @@
 package plan
 
 import (
+	"container/heap"
 	"io"
 	"sort"
 
 	"gopkg.in/sqle/sqle.v0/sql"
 )
 
+// Sort sorts the rows of its child. Rows are sorted in memory until they
+// exceed MemoryBudget, then each batch of sorted rows is spilled to disk as a
+// run and the runs are merged once the child is exhausted.
 type Sort struct {
 	UnaryNode
 	SortFields []SortField
+	// MemoryBudget is the maximum number of bytes of rows kept in memory.
+	MemoryBudget int
 }
 
 type SortOrder byte
@@ -34,8 +40,9 @@ type SortField struct {
 
 func NewSort(sortFields []SortField, child sql.Node) *Sort {
 	return &Sort{
-		UnaryNode:  UnaryNode{child},
-		SortFields: sortFields,
+		UnaryNode:    UnaryNode{child},
+		SortFields:   sortFields,
+		MemoryBudget: DefaultMemoryBudget,
 	}
 }
 
@@ -64,6 +71,7 @@ func (s *Sort) RowIter() (sql.RowIter, error) {
 func (s *Sort) TransformUp(f func(sql.Node) sql.Node) sql.Node {
 	c := s.UnaryNode.Child.TransformUp(f)
 	n := NewSort(s.SortFields, c)
+	n.MemoryBudget = s.MemoryBudget
 
 	return f(n)
 }
@@ -75,70 +83,243 @@ func (s *Sort) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql
 		sfs = append(sfs, SortField{sf.Column.TransformUp(f), sf.Order, sf.NullOrdering})
 	}
 	n := NewSort(sfs, c)
+	n.MemoryBudget = s.MemoryBudget
 
 	return n
 }
 
 type sortIter struct {
-	s          *Sort
-	childIter  sql.RowIter
-	sortedRows []sql.Row
-	idx        int
+	s         *Sort
+	childIter sql.RowIter
+	sorted    sql.RowIter
+	runs      []*spillFile
 }
 
 func newSortIter(s *Sort, child sql.RowIter) *sortIter {
 	return &sortIter{
-		s:          s,
-		childIter:  child,
-		sortedRows: nil,
-		idx:        -1,
+		s:         s,
+		childIter: child,
 	}
 }
 
 func (i *sortIter) Next() (sql.Row, error) {
-	if i.idx == -1 {
-		err := i.computeSortedRows()
+	if i.sorted == nil {
+		sorted, err := i.computeSortedRows()
 		if err != nil {
+			_ = i.closeRuns()
 			return nil, err
 		}
-		i.idx = 0
-	}
-	if i.idx >= len(i.sortedRows) {
-		return nil, io.EOF
+
+		i.sorted = sorted
 	}
-	row := i.sortedRows[i.idx]
-	i.idx++
-	return row, nil
+
+	return i.sorted.Next()
 }
 
 func (i *sortIter) Close() error {
-	i.sortedRows = nil
-	return i.childIter.Close()
+	err := i.childIter.Close()
+	if cerr := i.closeRuns(); err == nil {
+		err = cerr
+	}
+
+	return err
 }
 
-func (i *sortIter) computeSortedRows() error {
-	rows := []sql.Row{}
+func (i *sortIter) closeRuns() error {
+	var err error
+	for _, r := range i.runs {
+		if cerr := r.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	i.runs = nil
+	return err
+}
+
+// computeSortedRows reads all the rows of the child and returns an iterator
+// over them in order. If they don't fit in the memory budget, the sorted runs
+// spilled to disk are merged with the rows left in memory.
+func (i *sortIter) computeSortedRows() (sql.RowIter, error) {
+	var rows []sql.Row
+	var size int
 	for {
 		childRow, err := i.childIter.Next()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
+
 		rows = append(rows, childRow)
+		size += rowSize(childRow)
+		if size > i.s.MemoryBudget {
+			if err := i.spill(rows); err != nil {
+				return nil, err
+			}
+
+			rows, size = nil, 0
+		}
+	}
+
+	if err := i.sort(rows); err != nil {
+		return nil, err
+	}
+
+	if len(i.runs) == 0 {
+		return sql.RowsToRowIter(rows...), nil
+	}
+
+	iters := make([]sql.RowIter, 0, len(i.runs)+1)
+	for _, r := range i.runs {
+		iter, err := r.RowIter()
+		if err != nil {
+			return nil, err
+		}
+
+		iters = append(iters, iter)
 	}
-	sort.Sort(&sorter{
+
+	iters = append(iters, sql.RowsToRowIter(rows...))
+	return newMergeIter(i.s.SortFields, iters)
+}
+
+func (i *sortIter) sort(rows []sql.Row) error {
+	sorter := &sorter{
 		sortFields: i.s.SortFields,
 		rows:       rows,
-	})
-	i.sortedRows = rows
+	}
+	sort.Stable(sorter)
+	return sorter.lastError
+}
+
+// spill sorts the rows and writes them to a new run.
+func (i *sortIter) spill(rows []sql.Row) error {
+	if err := i.sort(rows); err != nil {
+		return err
+	}
+
+	run, err := newSpillFile()
+	if err != nil {
+		return err
+	}
+
+	i.runs = append(i.runs, run)
+	for _, row := range rows {
+		if err := run.Write(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeIter merges several sorted iterators into a sorted one. Rows in the
+// same position according to the sort fields are returned in the order of
+// the iterators they come from.
+type mergeIter struct {
+	sortFields []SortField
+	heads      []mergeHead
+	err        error
+}
+
+// mergeHead is the next row of one of the iterators being merged.
+type mergeHead struct {
+	row  sql.Row
+	iter sql.RowIter
+	seq  int
+}
+
+func newMergeIter(sortFields []SortField, iters []sql.RowIter) (*mergeIter, error) {
+	m := &mergeIter{sortFields: sortFields}
+	for seq, iter := range iters {
+		row, err := iter.Next()
+		if err == io.EOF {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.heads = append(m.heads, mergeHead{row, iter, seq})
+	}
+
+	heap.Init(m)
+	return m, m.err
+}
+
+func (m *mergeIter) Next() (sql.Row, error) {
+	if len(m.heads) == 0 {
+		return nil, io.EOF
+	}
+
+	head := &m.heads[0]
+	row := head.row
+	next, err := head.iter.Next()
+	switch {
+	case err == io.EOF:
+		heap.Pop(m)
+	case err != nil:
+		return nil, err
+	default:
+		head.row = next
+		heap.Fix(m, 0)
+	}
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return row, nil
+}
+
+func (m *mergeIter) Close() error {
 	return nil
 }
 
+func (m *mergeIter) Len() int {
+	return len(m.heads)
+}
+
+func (m *mergeIter) Less(i, j int) bool {
+	if m.err != nil {
+		return false
+	}
+
+	a, b := m.heads[i], m.heads[j]
+	cmp, err := compareRows(m.sortFields, a.row, b.row)
+	if err != nil {
+		m.err = err
+		return false
+	}
+
+	if cmp == 0 {
+		return a.seq < b.seq
+	}
+
+	return cmp < 0
+}
+
+func (m *mergeIter) Swap(i, j int) {
+	m.heads[i], m.heads[j] = m.heads[j], m.heads[i]
+}
+
+func (m *mergeIter) Push(x interface{}) {
+	m.heads = append(m.heads, x.(mergeHead))
+}
+
+func (m *mergeIter) Pop() interface{} {
+	h := m.heads[len(m.heads)-1]
+	m.heads = m.heads[:len(m.heads)-1]
+	return h
+}
+
 type sorter struct {
 	sortFields []SortField
 	rows       []sql.Row
+	lastError  error
 }
 
 func (s *sorter) Len() int {
@@ -150,32 +331,55 @@ func (s *sorter) Swap(i, j int) {
 }
 
 func (s *sorter) Less(i, j int) bool {
-	a := s.rows[i]
-	b := s.rows[j]
-	for _, sf := range s.sortFields {
+	if s.lastError != nil {
+		return false
+	}
+
+	cmp, err := compareRows(s.sortFields, s.rows[i], s.rows[j])
+	if err != nil {
+		s.lastError = err
+		return false
+	}
+
+	return cmp < 0
+}
+
+// compareRows returns a negative number, zero or a positive number if the
+// row a goes before, in the same position or after the row b according to
+// the sort fields.
+func compareRows(sortFields []SortField, a, b sql.Row) (int, error) {
+	for _, sf := range sortFields {
 		typ := sf.Column.Type()
-		av := sf.Column.Eval(a)
-		bv := sf.Column.Eval(b)
+		av, err := sf.Column.Eval(a)
+		if err != nil {
+			return 0, err
+		}
+
+		bv, err := sf.Column.Eval(b)
+		if err != nil {
+			return 0, err
+		}
 
-		if av == nil {
-			return sf.NullOrdering == NullsFirst
+		if av == nil && bv == nil {
+			continue
 		}
 
-		if bv == nil {
-			return sf.NullOrdering != NullsFirst
+		if av == nil || bv == nil {
+			if (av == nil) == (sf.NullOrdering == NullsFirst) {
+				return -1, nil
+			}
+
+			return 1, nil
 		}
 
 		if sf.Order == Descending {
 			av, bv = bv, av
 		}
 
-		switch typ.Compare(av, bv) {
-		case -1:
-			return true
-		case 1:
-			return false
+		if cmp := typ.Compare(av, bv); cmp != 0 {
+			return cmp, nil
 		}
 	}
 
-	return false
+	return 0, nil
 }
@@ -1,6 +1,8 @@
 package plan
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
 	"gopkg.in/sqle/sqle.v0/mem"
@@ -36,7 +38,7 @@ func TestSort(t *testing.T) {
 		{Column: expression.NewGetField(0, sql.String, "col1", true), Order: Descending, NullOrdering: NullsLast},
 	}
 	s := NewSort(sf, child)
-	require.Equal(schema, s.Schema())
+	require.Equal(child.Schema(), s.Schema())
 
 	expected := []sql.Row{
 		sql.NewRow("c", nil),
@@ -75,7 +77,7 @@ func TestSortAscending(t *testing.T) {
 		{Column: expression.NewGetField(0, sql.String, "col1", true), Order: Ascending, NullOrdering: NullsFirst},
 	}
 	s := NewSort(sf, child)
-	require.Equal(schema, s.Schema())
+	require.Equal(child.Schema(), s.Schema())
 
 	expected := []sql.Row{
 		sql.NewRow(nil),
@@ -114,7 +116,7 @@ func TestSortDescending(t *testing.T) {
 		{Column: expression.NewGetField(0, sql.String, "col1", true), Order: Descending, NullOrdering: NullsFirst},
 	}
 	s := NewSort(sf, child)
-	require.Equal(schema, s.Schema())
+	require.Equal(child.Schema(), s.Schema())
 
 	expected := []sql.Row{
 		sql.NewRow(nil),
@@ -128,3 +130,87 @@ func TestSortDescending(t *testing.T) {
 	require.NoError(err)
 	require.Equal(expected, actual)
 }
+
+func TestSort_Spill(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{
+		{Name: "col1", Type: sql.Integer, Nullable: true},
+		{Name: "col2", Type: sql.String, Nullable: false},
+	}
+
+	child := mem.NewTable("test", schema)
+	var expected []sql.Row
+	for i := 0; i < 100; i++ {
+		row := sql.NewRow(int32(i%10), fmt.Sprint(i))
+		require.NoError(child.Insert(row))
+		expected = append(expected, row)
+	}
+
+	require.NoError(child.Insert(sql.NewRow(nil, "null")))
+	expected = append([]sql.Row{sql.NewRow(nil, "null")}, expected...)
+
+	// rows with the same value keep the order they had in the child
+	sort.SliceStable(expected[1:], func(i, j int) bool {
+		return expected[i+1][0].(int32) < expected[j+1][0].(int32)
+	})
+
+	sf := []SortField{
+		{Column: expression.NewGetField(0, sql.Integer, "col1", true), Order: Ascending, NullOrdering: NullsFirst},
+	}
+
+	before := spillFiles(t)
+
+	s := NewSort(sf, child)
+	s.MemoryBudget = 200
+	actual, err := sql.NodeToRows(s)
+	require.NoError(err)
+	require.Equal(expected, actual)
+
+	require.Equal(before, spillFiles(t))
+
+	// the runs already spilled are removed when sorting fails
+	child = mem.NewTable("test", sql.Schema{{Name: "col1", Type: sql.Integer}})
+	for i := 100; i >= 0; i-- {
+		require.NoError(child.Insert(sql.NewRow(int32(i))))
+	}
+
+	sf = []SortField{{
+		Column: expression.NewDiv(
+			expression.NewLiteral(int32(1), sql.Integer),
+			expression.NewGetField(0, sql.Integer, "col1", false),
+		),
+		Order: Ascending,
+	}}
+
+	s = NewSort(sf, child)
+	s.MemoryBudget = 200
+	_, err = sql.NodeToRows(s)
+	require.Equal(expression.ErrDivisionByZero, err)
+
+	require.Equal(before, spillFiles(t))
+}
+
+func TestSort_TransformUp(t *testing.T) {
+	require := require.New(t)
+
+	sf := []SortField{
+		{Column: expression.NewGetField(0, sql.Integer, "col1", true), Order: Ascending},
+	}
+
+	s := NewSort(sf, NewUnresolvedTable("foo"))
+	s.MemoryBudget = 5
+
+	table := mem.NewTable("foo", sql.Schema{})
+	n := s.TransformUp(func(n sql.Node) sql.Node {
+		if _, ok := n.(*UnresolvedTable); ok {
+			return table
+		}
+
+		return n
+	})
+
+	expected := NewSort(sf, table)
+	expected.MemoryBudget = 5
+	require.Equal(expected, n)
+}
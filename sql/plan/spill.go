@@ -0,0 +1,149 @@
+package plan
+
+import (
+	"bufio"
+	"encoding/gob"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+func init() {
+	// time.Time is the only type of row values gob does not know about, and
+	// the set of values is the only such type of aggregation buffers.
+	gob.Register(time.Time{})
+	gob.Register(map[string]struct{}{})
+}
+
+// spillFile stores rows in a temporary file, so they don't need to be kept
+// in memory. Rows are read back in the order they were written.
+type spillFile struct {
+	f   *os.File
+	w   *bufio.Writer
+	enc *gob.Encoder
+}
+
+func newSpillFile() (*spillFile, error) {
+	f, err := ioutil.TempFile("", "sqle-spill-")
+	if err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(f)
+	return &spillFile{f: f, w: w, enc: gob.NewEncoder(w)}, nil
+}
+
+// Write appends a row to the file.
+func (s *spillFile) Write(row sql.Row) error {
+	return s.enc.Encode(row)
+}
+
+// RowIter returns an iterator over all the rows written to the file. No more
+// rows can be written after calling it.
+func (s *spillFile) RowIter() (sql.RowIter, error) {
+	if err := s.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return &spillFileIter{gob.NewDecoder(bufio.NewReader(s.f))}, nil
+}
+
+// Close closes and removes the file.
+func (s *spillFile) Close() error {
+	err := s.f.Close()
+	if rerr := os.Remove(s.f.Name()); err == nil {
+		err = rerr
+	}
+
+	return err
+}
+
+type spillFileIter struct {
+	dec *gob.Decoder
+}
+
+func (i *spillFileIter) Next() (sql.Row, error) {
+	var row sql.Row
+	if err := i.dec.Decode(&row); err != nil {
+		return nil, err
+	}
+
+	return row, nil
+}
+
+func (i *spillFileIter) Close() error {
+	return nil
+}
+
+// spillPartitions distributes rows between several spill files by the hash
+// of a key, so all rows with the same key end up in the same partition and
+// each partition can be processed on its own.
+type spillPartitions []*spillFile
+
+func newSpillPartitions(n int) (spillPartitions, error) {
+	p := make(spillPartitions, 0, n)
+	for i := 0; i < n; i++ {
+		f, err := newSpillFile()
+		if err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+
+		p = append(p, f)
+	}
+
+	return p, nil
+}
+
+// Write appends a row to the partition of the given key.
+func (p spillPartitions) Write(key string, row sql.Row) error {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return p[int(h.Sum32()%uint32(len(p)))].Write(row)
+}
+
+// Close closes and removes the files of all partitions.
+func (p spillPartitions) Close() error {
+	var err error
+	for _, f := range p {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// DefaultMemoryBudget is the default number of bytes of rows a node keeps in
+// memory before spilling them to disk.
+const DefaultMemoryBudget = 64 << 20
+
+// rowSize returns an estimation of the number of bytes the row takes in
+// memory.
+func rowSize(row sql.Row) int {
+	size := 24
+	for _, v := range row {
+		switch v := v.(type) {
+		case string:
+			size += 16 + len(v)
+		case []byte:
+			size += 24 + len(v)
+		case map[string]struct{}:
+			// sets of distinct values are estimated by their length only,
+			// so they can be measured after every update.
+			size += 48 + 64*len(v)
+		default:
+			size += 16
+		}
+	}
+
+	return size
+}
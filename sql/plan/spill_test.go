@@ -0,0 +1,79 @@
+package plan
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpillFile(t *testing.T) {
+	require := require.New(t)
+
+	f, err := newSpillFile()
+	require.NoError(err)
+
+	now := time.Unix(1500000000, 0).UTC()
+	rows := []sql.Row{
+		sql.NewRow(int32(1), int64(2), "foo", nil),
+		sql.NewRow(1.5, true, now, []byte("bar")),
+	}
+
+	for _, r := range rows {
+		require.NoError(f.Write(r))
+	}
+
+	it, err := f.RowIter()
+	require.NoError(err)
+
+	result, err := sql.RowIterToRows(it)
+	require.NoError(err)
+	require.Equal(rows, result)
+
+	name := f.f.Name()
+	require.NoError(f.Close())
+	_, err = os.Stat(name)
+	require.True(os.IsNotExist(err))
+}
+
+func TestSpillPartitions(t *testing.T) {
+	require := require.New(t)
+
+	p, err := newSpillPartitions(4)
+	require.NoError(err)
+	require.Len(p, 4)
+
+	require.NoError(p.Write("foo", sql.NewRow("foo", int32(1))))
+	require.NoError(p.Write("foo", sql.NewRow("foo", int32(2))))
+	require.NoError(p.Write("bar", sql.NewRow("bar", int32(3))))
+
+	var total int
+	for _, f := range p {
+		it, err := f.RowIter()
+		require.NoError(err)
+
+		var foos int
+		for {
+			row, err := it.Next()
+			if err == io.EOF {
+				break
+			}
+
+			require.NoError(err)
+			total++
+			if row[0] == "foo" {
+				foos++
+			}
+		}
+
+		// rows with the same key are always in the same partition
+		require.Contains([]int{0, 2}, foos)
+	}
+
+	require.Equal(3, total)
+	require.NoError(p.Close())
+}
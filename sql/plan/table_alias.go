@@ -0,0 +1,47 @@
+package plan
+
+import "gopkg.in/sqle/sqle.v0/sql"
+
+// TableAlias gives a different name to its child, usually a table. The
+// columns of its schema have the alias as their source, so they can be
+// referenced with it.
+type TableAlias struct {
+	UnaryNode
+	name string
+}
+
+// NewTableAlias creates a new TableAlias with the given name.
+func NewTableAlias(name string, child sql.Node) *TableAlias {
+	return &TableAlias{UnaryNode{child}, name}
+}
+
+// Name returns the alias.
+func (t *TableAlias) Name() string {
+	return t.name
+}
+
+func (t *TableAlias) Schema() sql.Schema {
+	schema := t.Child.Schema()
+	result := make(sql.Schema, len(schema))
+	for i, c := range schema {
+		nc := *c
+		nc.Source = t.name
+		result[i] = &nc
+	}
+
+	return result
+}
+
+func (t *TableAlias) RowIter() (sql.RowIter, error) {
+	return t.Child.RowIter()
+}
+
+func (t *TableAlias) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := t.Child.TransformUp(f)
+	return f(NewTableAlias(t.name, c))
+}
+
+func (t *TableAlias) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := t.Child.TransformExpressionsUp(f)
+	return NewTableAlias(t.name, c)
+}
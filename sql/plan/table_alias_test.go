@@ -0,0 +1,42 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableAlias(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("bar", sql.Schema{
+		{Name: "a", Type: sql.String},
+		{Name: "b", Type: sql.String},
+	})
+	require.NoError(table.Insert(sql.NewRow("1", "2")))
+	require.NoError(table.Insert(sql.NewRow("3", "4")))
+
+	alias := NewTableAlias("foo", table)
+	require.Equal("foo", alias.Name())
+	require.True(alias.Resolved())
+
+	require.Equal(sql.Schema{
+		{Name: "a", Type: sql.String, Source: "foo"},
+		{Name: "b", Type: sql.String, Source: "foo"},
+	}, alias.Schema())
+
+	// the schema of the table must not be modified
+	require.Equal("bar", table.Schema()[0].Source)
+
+	rows, err := sql.NodeToRows(alias)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		sql.NewRow("1", "2"),
+		sql.NewRow("3", "4"),
+	}, rows)
+
+	require.False(NewTableAlias("foo", NewUnresolvedTable("bar")).Resolved())
+}
@@ -0,0 +1,202 @@
+package plan
+
+import (
+	"container/heap"
+	"io"
+	"sort"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+)
+
+// TopN returns the first rows of its child sorted by the sort fields, up to
+// a maximum number of rows. It's equivalent to a Limit over a Sort, but only
+// the rows that may be returned are kept in memory.
+type TopN struct {
+	UnaryNode
+	SortFields []SortField
+	Limit      sql.Expression
+}
+
+// NewTopN creates a new TopN node returning at most the number of rows given
+// by limit, which is evaluated when the rows are requested.
+func NewTopN(sortFields []SortField, limit sql.Expression, child sql.Node) *TopN {
+	return &TopN{UnaryNode{child}, sortFields, limit}
+}
+
+func (p *TopN) Resolved() bool {
+	if !p.Child.Resolved() || !p.Limit.Resolved() {
+		return false
+	}
+
+	for _, f := range p.SortFields {
+		if !f.Column.Resolved() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *TopN) RowIter() (sql.RowIter, error) {
+	limit, err := evalRowCount(p.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := p.Child.RowIter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &topNIter{p: p, limit: limit, childIter: i}, nil
+}
+
+func (p *TopN) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.Child.TransformUp(f)
+	return f(NewTopN(p.SortFields, p.Limit, c))
+}
+
+func (p *TopN) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.Child.TransformExpressionsUp(f)
+	var sfs []SortField
+	for _, sf := range p.SortFields {
+		sfs = append(sfs, SortField{sf.Column.TransformUp(f), sf.Order, sf.NullOrdering})
+	}
+
+	return NewTopN(sfs, p.Limit.TransformUp(f), c)
+}
+
+type topNIter struct {
+	p         *TopN
+	limit     int64
+	childIter sql.RowIter
+	rows      []sql.Row
+	idx       int
+	computed  bool
+}
+
+func (i *topNIter) Next() (sql.Row, error) {
+	if !i.computed {
+		if err := i.computeRows(); err != nil {
+			return nil, err
+		}
+
+		i.computed = true
+	}
+
+	if i.idx >= len(i.rows) {
+		return nil, io.EOF
+	}
+
+	row := i.rows[i.idx]
+	i.idx++
+	return row, nil
+}
+
+func (i *topNIter) Close() error {
+	i.rows = nil
+	return i.childIter.Close()
+}
+
+// computeRows reads the rows of the child keeping the first ones in a heap
+// whose top is the last of them, so it can be replaced by rows that go
+// before it.
+func (i *topNIter) computeRows() error {
+	if i.limit == 0 {
+		return nil
+	}
+
+	h := &topNHeap{sortFields: i.p.SortFields}
+	var seq int64
+	for {
+		row, err := i.childIter.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		r := topNRow{row, seq}
+		seq++
+
+		if int64(h.Len()) < i.limit {
+			heap.Push(h, r)
+		} else if h.less(r, h.rows[0]) {
+			h.rows[0] = r
+			heap.Fix(h, 0)
+		}
+
+		if h.err != nil {
+			return h.err
+		}
+	}
+
+	sort.Sort(sort.Reverse(h))
+	if h.err != nil {
+		return h.err
+	}
+
+	i.rows = make([]sql.Row, len(h.rows))
+	for j, r := range h.rows {
+		i.rows[j] = r.row
+	}
+
+	return nil
+}
+
+// topNRow is a row with its position in the child, so rows in the same
+// position according to the sort fields are returned in the same order as
+// they were read.
+type topNRow struct {
+	row sql.Row
+	seq int64
+}
+
+// topNHeap is a max-heap of rows according to the sort fields.
+type topNHeap struct {
+	sortFields []SortField
+	rows       []topNRow
+	err        error
+}
+
+func (h *topNHeap) less(a, b topNRow) bool {
+	if h.err != nil {
+		return false
+	}
+
+	cmp, err := compareRows(h.sortFields, a.row, b.row)
+	if err != nil {
+		h.err = err
+		return false
+	}
+
+	if cmp == 0 {
+		return a.seq < b.seq
+	}
+
+	return cmp < 0
+}
+
+func (h *topNHeap) Len() int {
+	return len(h.rows)
+}
+
+func (h *topNHeap) Less(i, j int) bool {
+	return h.less(h.rows[j], h.rows[i])
+}
+
+func (h *topNHeap) Swap(i, j int) {
+	h.rows[i], h.rows[j] = h.rows[j], h.rows[i]
+}
+
+func (h *topNHeap) Push(x interface{}) {
+	h.rows = append(h.rows, x.(topNRow))
+}
+
+func (h *topNHeap) Pop() interface{} {
+	r := h.rows[len(h.rows)-1]
+	h.rows = h.rows[:len(h.rows)-1]
+	return r
+}
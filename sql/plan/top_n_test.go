@@ -0,0 +1,70 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopN(t *testing.T) {
+	require := require.New(t)
+
+	schema := sql.Schema{
+		{Name: "col1", Type: sql.String, Nullable: true},
+		{Name: "col2", Type: sql.Integer, Nullable: true},
+	}
+
+	child := mem.NewTable("test", schema)
+	for _, row := range []sql.Row{
+		sql.NewRow("a", int32(3)),
+		sql.NewRow("b", nil),
+		sql.NewRow("c", int32(1)),
+		sql.NewRow("d", int32(3)),
+		sql.NewRow("e", int32(2)),
+		sql.NewRow("f", int32(1)),
+	} {
+		require.NoError(child.Insert(row))
+	}
+
+	sf := []SortField{
+		{Column: expression.NewGetField(1, sql.Integer, "col2", true), Order: Descending, NullOrdering: NullsLast},
+	}
+
+	testCases := []struct {
+		limit    int64
+		expected []sql.Row
+	}{
+		{0, nil},
+		{1, []sql.Row{sql.NewRow("a", int32(3))}},
+		// rows in the same position keep the order of the child
+		{3, []sql.Row{
+			sql.NewRow("a", int32(3)),
+			sql.NewRow("d", int32(3)),
+			sql.NewRow("e", int32(2)),
+		}},
+		{10, []sql.Row{
+			sql.NewRow("a", int32(3)),
+			sql.NewRow("d", int32(3)),
+			sql.NewRow("e", int32(2)),
+			sql.NewRow("c", int32(1)),
+			sql.NewRow("f", int32(1)),
+			sql.NewRow("b", nil),
+		}},
+	}
+
+	for _, tt := range testCases {
+		n := NewTopN(sf, expression.NewLiteral(tt.limit, sql.BigInteger), child)
+		require.Equal(child.Schema(), n.Schema())
+
+		rows, err := sql.NodeToRows(n)
+		require.NoError(err)
+		require.Equal(tt.expected, rows, "limit %d", tt.limit)
+	}
+
+	_, err := NewTopN(sf, expression.NewLiteral(nil, sql.Null), child).RowIter()
+	require.Error(err)
+}
@@ -8,10 +8,19 @@ import (
 
 type UnresolvedTable struct {
 	Name string
+	// Database is the database of the table. If empty, the current
+	// database is used.
+	Database string
 }
 
 func NewUnresolvedTable(name string) *UnresolvedTable {
-	return &UnresolvedTable{name}
+	return &UnresolvedTable{Name: name}
+}
+
+// NewUnresolvedQualifiedTable creates a new UnresolvedTable of the given
+// database.
+func NewUnresolvedQualifiedTable(db, name string) *UnresolvedTable {
+	return &UnresolvedTable{Name: name, Database: db}
 }
 
 func (*UnresolvedTable) Resolved() bool {
@@ -31,7 +40,7 @@ func (*UnresolvedTable) RowIter() (sql.RowIter, error) {
 }
 
 func (p *UnresolvedTable) TransformUp(f func(sql.Node) sql.Node) sql.Node {
-	return f(NewUnresolvedTable(p.Name))
+	return f(NewUnresolvedQualifiedTable(p.Database, p.Name))
 }
 
 func (p *UnresolvedTable) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
@@ -13,3 +13,13 @@ func TestUnresolvedTable(t *testing.T) {
 	var n sql.Node = NewUnresolvedTable("test_table")
 	assert.NotNil(n)
 }
+
+func TestUnresolvedQualifiedTable(t *testing.T) {
+	assert := assert.New(t)
+	n := NewUnresolvedQualifiedTable("db", "test_table")
+	assert.Equal("db", n.Database)
+	assert.Equal("test_table", n.Name)
+
+	var transformed = n.TransformUp(func(n sql.Node) sql.Node { return n })
+	assert.Equal(n, transformed)
+}
@@ -0,0 +1,160 @@
+package plan
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+)
+
+// UpdateField is a column to update and the expression whose value is
+// assigned to it. Both are evaluated against the rows to update.
+type UpdateField struct {
+	Column sql.Expression
+	Value  sql.Expression
+}
+
+// Update sets new values for the columns of the rows returned by its child,
+// which reads them from a table implementing sql.Updater.
+type Update struct {
+	UnaryNode
+	Fields []UpdateField
+}
+
+// NewUpdate creates a new Update node.
+func NewUpdate(fields []UpdateField, child sql.Node) *Update {
+	return &Update{
+		UnaryNode: UnaryNode{Child: child},
+		Fields:    fields,
+	}
+}
+
+func (p *Update) Resolved() bool {
+	if !p.Child.Resolved() {
+		return false
+	}
+
+	for _, f := range p.Fields {
+		if !expressionsResolved(f.Column, f.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *Update) Schema() sql.Schema {
+	return sql.Schema{{
+		Name:     "updated",
+		Type:     sql.BigInteger,
+		Default:  int64(0),
+		Nullable: false,
+	}}
+}
+
+// Execute updates the rows and returns the number of rows changed. Rows that
+// already had the new values are not counted.
+func (p *Update) Execute() (sql.Result, error) {
+	var result sql.Result
+	table, err := findTable(p.Child)
+	if err != nil {
+		return result, err
+	}
+
+	updater, ok := table.(sql.Updater)
+	if !ok {
+		return result, fmt.Errorf("table %s does not support UPDATE", table.Name())
+	}
+
+	schema := p.Child.Schema()
+	columns := make([]int, len(p.Fields))
+	for i, f := range p.Fields {
+		gf, ok := f.Column.(*expression.GetField)
+		if !ok {
+			return result, fmt.Errorf("can't update expression %s", f.Column.Name())
+		}
+
+		columns[i] = gf.Index()
+	}
+
+	// All the rows are read before updating any of them, and they are all
+	// replaced at once, so the updates can't change the rows that are going
+	// to be updated.
+	rows, err := sql.NodeToRows(p.Child)
+	if err != nil {
+		return result, err
+	}
+
+	var oldRows, newRows []sql.Row
+	for _, row := range rows {
+		newRow := row.Copy()
+		for i, f := range p.Fields {
+			v, err := f.Value.Eval(row)
+			if err != nil {
+				return result, err
+			}
+
+			newRow[columns[i]] = v
+		}
+
+		if err := convertRow(schema, newRow); err != nil {
+			return result, err
+		}
+
+		if reflect.DeepEqual(row, newRow) {
+			continue
+		}
+
+		oldRows = append(oldRows, row)
+		newRows = append(newRows, newRow)
+	}
+
+	if len(oldRows) == 0 {
+		return result, nil
+	}
+
+	if err := updater.Update(oldRows, newRows); err != nil {
+		return result, err
+	}
+
+	result.RowsAffected = int64(len(oldRows))
+	return result, nil
+}
+
+func (p *Update) RowIter() (sql.RowIter, error) {
+	result, err := p.Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.RowsToRowIter(sql.NewRow(result.RowsAffected)), nil
+}
+
+func (p *Update) TransformUp(f func(sql.Node) sql.Node) sql.Node {
+	c := p.UnaryNode.Child.TransformUp(f)
+	return f(NewUpdate(p.Fields, c))
+}
+
+func (p *Update) TransformExpressionsUp(f func(sql.Expression) sql.Expression) sql.Node {
+	c := p.UnaryNode.Child.TransformExpressionsUp(f)
+	return NewUpdate(transformUpdateFields(f, p.Fields), c)
+}
+
+func transformUpdateFields(f func(sql.Expression) sql.Expression,
+	fields []UpdateField) []UpdateField {
+
+	if fields == nil {
+		return nil
+	}
+
+	result := make([]UpdateField, len(fields))
+	for i, field := range fields {
+		result[i] = UpdateField{
+			Column: field.Column.TransformUp(f),
+			Value:  field.Value.TransformUp(f),
+		}
+	}
+
+	return result
+}
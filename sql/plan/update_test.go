@@ -0,0 +1,75 @@
+package plan
+
+import (
+	"testing"
+
+	"gopkg.in/sqle/sqle.v0/mem"
+	"gopkg.in/sqle/sqle.v0/sql"
+	"gopkg.in/sqle/sqle.v0/sql/expression"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	require := require.New(t)
+
+	table := mem.NewTable("t", sql.Schema{
+		{Name: "a", Type: sql.BigInteger},
+		{Name: "b", Type: sql.String},
+	})
+	require.NoError(table.Insert(sql.NewRow(int64(1), "x")))
+	require.NoError(table.Insert(sql.NewRow(int64(2), "y")))
+	require.NoError(table.Insert(sql.NewRow(int64(3), "y")))
+
+	a := expression.NewGetFieldWithTable(0, sql.BigInteger, "t", "a", false)
+	b := expression.NewGetFieldWithTable(1, sql.String, "t", "b", false)
+
+	update := NewUpdate(
+		[]UpdateField{
+			{Column: a, Value: expression.NewPlus(a, expression.NewLiteral(int32(10), sql.Integer))},
+			{Column: b, Value: expression.NewLiteral("y", sql.String)},
+		},
+		NewFilter(
+			expression.NewGreaterThan(a, expression.NewLiteral(int64(1), sql.BigInteger)),
+			table,
+		),
+	)
+	require.True(update.Resolved())
+
+	result, err := update.Execute()
+	require.NoError(err)
+	require.Equal(sql.Result{RowsAffected: 2}, result)
+
+	rows, err := sql.NodeToRows(table)
+	require.NoError(err)
+	require.Equal([]sql.Row{
+		sql.NewRow(int64(1), "x"),
+		sql.NewRow(int64(12), "y"),
+		sql.NewRow(int64(13), "y"),
+	}, rows)
+
+	// rows that already have the new values are not counted
+	update = NewUpdate(
+		[]UpdateField{{Column: b, Value: expression.NewLiteral("y", sql.String)}},
+		table,
+	)
+
+	rows, err = sql.NodeToRows(update)
+	require.NoError(err)
+	require.Equal([]sql.Row{sql.NewRow(int64(1))}, rows)
+}
+
+func TestUpdate_NotUpdatable(t *testing.T) {
+	require := require.New(t)
+
+	update := NewUpdate(
+		[]UpdateField{{
+			Column: expression.NewGetField(0, sql.String, "name", false),
+			Value:  expression.NewLiteral("x", sql.String),
+		}},
+		NewShowTables(nil),
+	)
+
+	_, err := update.Execute()
+	require.Error(err)
+}
@@ -49,7 +49,12 @@ func (p *Values) RowIter() (sql.RowIter, error) {
 	for i, et := range p.ExpressionTuples {
 		vals := make([]interface{}, len(et))
 		for j, e := range et {
-			vals[j] = e.Eval(nil)
+			v, err := e.Eval(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			vals[j] = v
 		}
 
 		rows[i] = sql.NewRow(vals...)
@@ -47,6 +47,15 @@ type Column struct {
 	// Nullable is true if the column can contain NULL values, or false
 	// otherwise.
 	Nullable bool
+	// Source is the name of the table or alias the column belongs to, or
+	// empty if it does not belong to any.
+	Source string
+	// PrimaryKey is true if the column is part of the primary key of the
+	// table.
+	PrimaryKey bool
+	// Unique is true if no two rows of the table can have the same non-NULL
+	// value in the column.
+	Unique bool
 }
 
 func (c *Column) Check(v interface{}) bool {
@@ -331,6 +340,8 @@ func convertToString(v interface{}) (interface{}, error) {
 	switch v.(type) {
 	case string:
 		return v.(string), nil
+	case []byte:
+		return string(v.([]byte)), nil
 	case fmt.Stringer:
 		return v.(fmt.Stringer).String(), nil
 	default:
@@ -491,22 +502,36 @@ func compareBool(a interface{}, b interface{}) int {
 }
 
 func checkFloat64(v interface{}) bool {
-	_, ok := v.(float32)
+	_, ok := v.(float64)
 	return ok
 }
 
 func convertToFloat64(v interface{}) (interface{}, error) {
 	switch v.(type) {
 	case float32:
-		return v.(float32), nil
+		return float64(v.(float32)), nil
+	case float64:
+		return v.(float64), nil
+	case int32:
+		return float64(v.(int32)), nil
+	case int64:
+		return float64(v.(int64)), nil
+	case int:
+		return float64(v.(int)), nil
+	case string:
+		f, err := strconv.ParseFloat(v.(string), 64)
+		if err != nil {
+			return nil, fmt.Errorf("value %q can't be converted to float64", v)
+		}
+		return f, nil
 	default:
 		return nil, ErrInvalidType
 	}
 }
 
 func compareFloat64(a interface{}, b interface{}) int {
-	av := a.(float32)
-	bv := b.(float32)
+	av := a.(float64)
+	bv := b.(float64)
 	if av < bv {
 		return -1
 	} else if av > bv {
@@ -524,6 +549,8 @@ const timestampLayout = "2006-01-02 15:04:05.000000"
 
 func convertToTimestamp(v interface{}) (interface{}, error) {
 	switch v.(type) {
+	case time.Time:
+		return v.(time.Time), nil
 	case string:
 		t, err := time.Parse(timestampLayout, v.(string))
 		if err != nil {
@@ -2,6 +2,7 @@ package sql
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -16,6 +17,9 @@ func TestType_String(t *testing.T) {
 	v, err = String.Convert("")
 	assert.Nil(err)
 	assert.Equal("", v)
+	v, err = String.Convert([]byte("foo"))
+	assert.Nil(err)
+	assert.Equal("foo", v)
 	v, err = String.Convert(1)
 	assert.Equal(ErrInvalidType, err)
 	assert.Nil(v)
@@ -91,3 +95,45 @@ func TestType_BigInteger(t *testing.T) {
 	assert.Equal(0, BigInteger.Compare(int64(1), int64(1)))
 	assert.Equal(1, BigInteger.Compare(int64(2), int64(1)))
 }
+
+func TestType_Float(t *testing.T) {
+	var v interface{}
+	var err error
+	assert := assert.New(t)
+	assert.True(Float.Check(float64(1)))
+	assert.False(Float.Check(float32(1)))
+	assert.False(Float.Check(int64(1)))
+	assert.False(Float.Check(""))
+	v, err = Float.Convert(float32(1.5))
+	assert.Nil(err)
+	assert.Equal(float64(1.5), v)
+	v, err = Float.Convert(int64(2))
+	assert.Nil(err)
+	assert.Equal(float64(2), v)
+	v, err = Float.Convert(int32(2))
+	assert.Nil(err)
+	assert.Equal(float64(2), v)
+	v, err = Float.Convert("2.5")
+	assert.Nil(err)
+	assert.Equal(float64(2.5), v)
+	v, err = Float.Convert("foo")
+	assert.NotNil(err)
+	assert.Nil(v)
+	v, err = Float.Convert(true)
+	assert.Equal(ErrInvalidType, err)
+	assert.Nil(v)
+	assert.Equal(-1, Float.Compare(float64(1), float64(2)))
+	assert.Equal(0, Float.Compare(float64(1), float64(1)))
+	assert.Equal(1, Float.Compare(float64(2), float64(1)))
+}
+
+func TestType_TimestampWithTimezone(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	v, err := TimestampWithTimezone.Convert(now)
+	assert.Nil(err)
+	assert.Equal(now, v)
+	v, err = TimestampWithTimezone.Convert("2017-01-02 03:04:05.000000")
+	assert.Nil(err)
+	assert.Equal(time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC), v)
+}